@@ -5,35 +5,92 @@ import (
 	"challenge/pkg/api/timercheck"
 	"challenge/pkg/config"
 	"challenge/pkg/grpc/challenge_server"
+	"challenge/pkg/http/httpclient"
+	"challenge/pkg/http/redirect_server"
+	"challenge/pkg/shortener"
+	"challenge/pkg/shortener/cache"
+	"challenge/pkg/shortener/fallback"
 	"challenge/pkg/timer"
+	"challenge/pkg/urlcheck"
+	"challenge/pkg/urlpolicy"
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"google.golang.org/grpc"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
-const defaultConfigPath = "./configs/server.yaml"
+const (
+	defaultConfigPath = "./configs/server.yaml"
+
+	shutdownTimeout = 10 * time.Second
+)
 
 func main() {
 	// Init config
 	cfg := config.MustLoadByPath(defaultConfigPath)
 
 	// Init and inject all dependencies
-	bil := bilty.NewBilty(cfg.BitlyOAuthToken, http.DefaultClient)
-	timerChecker := timercheck.NewTimerCheck(http.DefaultClient)
-	t := timer.NewTimer(*timerChecker)
+	clients := mustCreateHttpClients(cfg)
+	shortLinker, httpServer, store := mustCreateShortener(cfg, clients)
+	if cfg.Shortener.Cache.Enabled {
+		shortLinker = mustCreateCache(cfg, shortLinker)
+	}
+	t := timer.NewTimer(createTimerBackend(cfg, clients),
+		timer.WithQueue(cfg.Timer.QueueSize, timer.SlowPolicy(cfg.Timer.SlowPolicy)),
+		timer.WithResync(cfg.Timer.Resync),
+	)
+	expvar.Publish("timer_subscribers", expvar.Func(func() any {
+		return t.AllSubscribers()
+	}))
+	expvar.Publish("timer_dropped", expvar.Func(func() any {
+		timers, total := t.Dropped()
+		return map[string]any{"timers": timers, "total": total}
+	}))
 
 	// Create gRPC server
+	opts := []challenge_server.Option{
+		challenge_server.WithBatchConcurrency(cfg.Shortener.BatchConcurrency),
+		challenge_server.WithValidator(urlcheck.NewValidator(urlcheck.Rules{
+			Schemes:       cfg.Shortener.Validation.Schemes,
+			MaxLength:     cfg.Shortener.Validation.MaxLength,
+			RejectPrivate: cfg.Shortener.Validation.RejectPrivate,
+		})),
+	}
+	if policy := mustCreatePolicy(cfg); policy != nil {
+		opts = append(opts, challenge_server.WithPolicy(policy))
+	}
 	server := grpc.NewServer()
-	challenge_server.Register(server, bil, t)
+	challenge_server.Register(server, shortLinker, t, opts...)
 
 	// Start gRPC server
 	go mustRun(server, cfg.Port)
 
+	// Start http redirect server
+	if httpServer != nil {
+		go mustRunHTTP(httpServer)
+	}
+
+	// Start metrics server
+	var metricsServer *http.Server
+	if cfg.MetricsPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("GET /debug/vars", expvar.Handler())
+		metricsServer = &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.MetricsPort),
+			Handler: mux,
+		}
+		go mustRunHTTP(metricsServer)
+	}
+
 	// Gracefull shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -41,6 +98,22 @@ func main() {
 	sig := <-stop
 	log.Printf("starting gracefull shutdown. Signal: %v\n", sig)
 	server.GracefulStop()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range []*http.Server{httpServer, metricsServer} {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("failed to shutdown http server. err: %v\n", err)
+		}
+	}
+	// Redirects are finished, so clicks kept in memory can be persisted
+	if closer, ok := store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("failed to close link store. err: %v\n", err)
+		}
+	}
 
 	log.Println("gracefully stopped")
 }
@@ -56,3 +129,165 @@ func mustRun(server *grpc.Server, port int) {
 		panic(err)
 	}
 }
+
+func mustRunHTTP(server *http.Server) {
+	log.Printf("starting http server on %s\n", server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
+}
+
+// mustCreateShortener creates configured backend, wrapped with fallback chain when fallbacks are set
+//
+// Returned http server resolves self-hosted links and returned store keeps them,
+// both are nil when local backend is not used
+func mustCreateShortener(cfg *config.ServerConfig, clients *httpclient.Factory) (challenge_server.UrlShortener, *http.Server, shortener.Store) {
+	var backends []fallback.Backend
+	var httpServer *http.Server
+	var store shortener.Store
+	for _, name := range cfg.Shortener.Backends() {
+		var s shortener.UrlShortener
+		switch name {
+		case config.ShortenerBitly:
+			s = bilty.NewBilty(cfg.BitlyOAuthToken, clients.Client(config.ShortenerBitly, cfg.Bitly.Upstream.Timeout),
+				bilty.WithBaseUrl(cfg.Bitly.Upstream.BaseUrl),
+				bilty.WithUserAgent(cfg.Bitly.Upstream.UserAgent),
+				bilty.WithHeaders(cfg.Bitly.Upstream.Headers),
+				bilty.WithDomain(cfg.Bitly.Domain),
+				bilty.WithGroupGuid(cfg.Bitly.GroupGuid),
+				bilty.WithExpiration(cfg.Bitly.Expiration),
+				bilty.WithRateLimit(cfg.Bitly.RateLimit.Requests, cfg.Bitly.RateLimit.Per, cfg.Bitly.RateLimit.Burst),
+				bilty.WithRetry(cfg.Bitly.Retry.MaxRetries, cfg.Bitly.Retry.BaseDelay, cfg.Bitly.Retry.MaxDelay),
+			)
+		case config.ShortenerLocal:
+			// Self-hosted links are resolved by http server which shares link store with shortener
+			store = mustCreateStore(cfg)
+			if cfg.Shortener.Sweep.Interval > 0 {
+				go shortener.Sweep(context.Background(), store, cfg.Shortener.Sweep.Interval, cfg.Shortener.Sweep.Retention)
+			}
+			s = shortener.NewShortener(store, cfg.Shortener.BaseUrl)
+			httpServer = &http.Server{
+				Addr:    fmt.Sprintf(":%d", cfg.Shortener.HttpPort),
+				Handler: redirect_server.NewHandler(store, cfg.Shortener.RedirectStatus),
+			}
+		}
+		backends = append(backends, fallback.Backend{Name: name, Shortener: s})
+	}
+	if len(backends) == 1 {
+		return backends[0].Shortener, httpServer, store
+	}
+
+	chain := fallback.NewChain(backends, fallback.WithBreaker(cfg.Shortener.Breaker.Failures, cfg.Shortener.Breaker.Cooldown))
+	expvar.Publish("shortener_backends", expvar.Func(func() any {
+		return chain.Available()
+	}))
+
+	return chain, httpServer, store
+}
+
+// createTimerBackend creates configured timer engine
+func createTimerBackend(cfg *config.ServerConfig, clients *httpclient.Factory) timer.TimerBackend {
+	if cfg.Timer.Backend == config.TimerTimercheck {
+		return timercheck.NewTimerCheck(clients.Client(config.TimerTimercheck, cfg.Timercheck.Upstream.Timeout),
+			timercheck.WithBaseUrl(cfg.Timercheck.Upstream.BaseUrl),
+			timercheck.WithUserAgent(cfg.Timercheck.Upstream.UserAgent),
+			timercheck.WithHeaders(cfg.Timercheck.Upstream.Headers),
+		)
+	}
+
+	return timer.NewLocalEngine(cfg.Timer.Retention)
+}
+
+// mustCreateHttpClients creates factory of http clients of upstream APIs
+func mustCreateHttpClients(cfg *config.ServerConfig) *httpclient.Factory {
+	clients, err := httpclient.NewFactory(httpclient.Config{
+		Timeout:             cfg.HttpClient.Timeout,
+		MaxBodyBytes:        cfg.HttpClient.MaxBodyBytes,
+		MaxIdleConns:        cfg.HttpClient.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HttpClient.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.HttpClient.MaxConnsPerHost,
+		IdleConnTimeout:     cfg.HttpClient.IdleConnTimeout,
+		ProxyUrl:            cfg.HttpClient.ProxyUrl,
+		CAFile:              cfg.HttpClient.CAFile,
+		Debug:               cfg.HttpClient.Debug,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return clients
+}
+
+// mustCreatePolicy creates destination policy and reloads its rule files on SIGHUP
+//
+// It returns nil when no policy rules are configured
+func mustCreatePolicy(cfg *config.ServerConfig) *urlpolicy.Policy {
+	policyCfg := cfg.Shortener.Policy
+	files := urlpolicy.Files{
+		Blocklist: policyCfg.Blocklist,
+		Allowlist: policyCfg.Allowlist,
+		Patterns:  policyCfg.Patterns,
+	}
+	if files == (urlpolicy.Files{}) && !policyCfg.Reachability.Enabled {
+		return nil
+	}
+
+	var opts []urlpolicy.Option
+	if policyCfg.Reachability.Enabled {
+		opts = append(opts, urlpolicy.WithReachability(urlpolicy.PublicClient(policyCfg.Reachability.Timeout)))
+	}
+	policy, err := urlpolicy.NewPolicy(files, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			// Broken files must not stop the server, previous rules stay in effect
+			if err := policy.Reload(); err != nil {
+				log.Printf("failed to reload url policy. err: %v\n", err)
+				continue
+			}
+			log.Println("url policy reloaded")
+		}
+	}()
+
+	return policy
+}
+
+func mustCreateStore(cfg *config.ServerConfig) shortener.Store {
+	if cfg.Shortener.Store == config.StoreFile {
+		store, err := shortener.NewFileStore(cfg.Shortener.StorePath, shortener.WithClickBatch(cfg.Shortener.Clicks.Batch))
+		if err != nil {
+			panic(err)
+		}
+		if cfg.Shortener.Clicks.Interval > 0 {
+			go store.SyncClicks(context.Background(), cfg.Shortener.Clicks.Interval)
+		}
+		return store
+	}
+
+	return shortener.NewMemoryStore()
+}
+
+// mustCreateCache wraps shortener with cache and publishes cache hits and misses to expvar
+func mustCreateCache(cfg *config.ServerConfig, next challenge_server.UrlShortener) challenge_server.UrlShortener {
+	var opts []cache.Option
+	if cfg.Shortener.Cache.Path != "" {
+		store, err := cache.NewFileStore(cfg.Shortener.Cache.Path, cfg.Shortener.Cache.TTL, cfg.Shortener.Cache.MaxSize)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, cache.WithStore(store))
+	}
+
+	c := cache.NewCache(next, cfg.Shortener.Cache.TTL, cfg.Shortener.Cache.MaxSize, opts...)
+	expvar.Publish("shortener_cache", expvar.Func(func() any {
+		hits, misses := c.Stats()
+		return map[string]int64{"hits": hits, "misses": misses}
+	}))
+
+	return c
+}
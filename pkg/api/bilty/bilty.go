@@ -4,77 +4,616 @@ package bilty
 
 import (
 	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
-	ErrInternal = errors.New("internal library error")
-	ErrApiError = errors.New("api error")
+	ErrInternal        = errors.New("internal library error")
+	ErrApiError        = errors.New("api error")
+	ErrAliasTaken      = errors.New("custom bitlink already exists")
+	ErrUpgradeRequired = errors.New("bitly plan upgrade required")
+	ErrNotFound        = errors.New("bitlink not found")
+	ErrLinkExists      = errors.New("bitlink of long url already exists")
 )
 
 const (
-	host = "https://api-ssl.bitly.com"
+	defaultBaseUrl = "https://api-ssl.bitly.com"
 
-	shortenUrl = "/v4/shorten"
+	shortenUrl       = "/v4/shorten"
+	customBitlinkUrl = "/v4/custom_bitlinks"
+	expandUrl        = "/v4/expand"
+	bitlinksUrl      = "/v4/bitlinks/"
+	groupsUrl        = "/v4/groups"
+	bsdsUrl          = "/v4/bsds"
+
+	clicksPath        = "/clicks"
+	clicksSummaryPath = "/clicks/summary"
+
+	unitReferenceLayout = "2006-01-02T15:04:05-0700"
+	dateLayout          = "2006-01-02"
+
+	defaultDomain = "bit.ly"
+
+	// Error messages documented by API
+	msgAlreadyExists   = "ALREADY_EXISTS"
+	msgUpgradeRequired = "UPGRADE_REQUIRED"
 )
 
 type Bilty struct {
 	client *http.Client
 	Token  string `json:"token"`
+	// baseUrl of API, Bitly API is used if empty, userAgent and headers are sent with every request
+	baseUrl   string
+	userAgent string
+	headers   map[string]string
+
+	// Defaults of shorten requests, token's default group and bit.ly are used if empty
+	domain    string
+	groupGuid string
+	// expiration of bitlinks is supported by the plan, see WithExpiration
+	expiration bool
+
+	// Client side rate limit and retries of failed requests, see WithRateLimit and WithRetry
+	limiter        *limiter
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	sleep          func(time.Duration)
 }
 
-func NewBilty(token string, client *http.Client) *Bilty {
-	return &Bilty{
-		client: client,
-		Token:  token,
+// NewBilty creates API client, by default requests are not limited on client side and not retried
+func NewBilty(token string, client *http.Client, opts ...Option) *Bilty {
+	b := &Bilty{
+		client:         client,
+		Token:          token,
+		limiter:        newLimiter(0, 0),
+		retryBaseDelay: defaultRetryBaseDelay,
+		retryMaxDelay:  defaultRetryMaxDelay,
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
+
+	return b
 }
 
 // CreateShortLink creates a short link for the given long URL.
 //
 // It takes a longUrl string as a parameter and returns a shortened url string and an error.
 func (b *Bilty) CreateShortLink(longUrl string) (string, error) {
+	return b.CreateShortLinkContext(context.Background(), longUrl)
+}
 
-	bts, err := json.Marshal(CreateLinkRequest{
-		Link: longUrl,
-	})
+// CreateShortLinkContext is CreateShortLink which stops waiting for API when ctx is done
+func (b *Bilty) CreateShortLinkContext(ctx context.Context, longUrl string) (string, error) {
+	resp, _, err := b.shorten(ctx, CreateLinkRequest{Link: longUrl}, false)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return "", err
 	}
 
-	req, err := http.NewRequest("POST", host+shortenUrl, bytes.NewReader(bts))
+	return resp.ShortLink, nil
+}
+
+// CreateCustomShortLink creates a short link for the given long URL with custom back-half(alias), domain and group
+//
+// All of alias, domain and group are optional, empty domain and group are replaced with client defaults.
+// If alias is empty, bitlink with generated back-half is returned.
+// Custom back-half is attached with custom bitlinks API, so it's available only for paid plans.
+//
+// Bitlink is created before alias is attached to it, so newly created bitlink is deleted when alias can't be
+// attached. Creation counts against plan quota even then, and bitlink is left if deletion fails as well.
+//
+// ErrAliasTaken returned when custom bitlink with given alias already exists
+// ErrUpgradeRequired returned when current plan doesn't support custom bitlinks
+func (b *Bilty) CreateCustomShortLink(longUrl string, alias string, domain string, groupGuid string) (string, error) {
+	return b.CreateCustomShortLinkContext(context.Background(), longUrl, alias, domain, groupGuid)
+}
+
+// CreateCustomShortLinkContext is CreateCustomShortLink which stops waiting for API when ctx is done
+func (b *Bilty) CreateCustomShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, groupGuid string) (string, error) {
+	// Bitlink created for alias is deleted when alias can't be attached, so it must be told from existing one
+	resp, created, err := b.shorten(ctx, CreateLinkRequest{Link: longUrl, Domain: domain, GroupGuid: groupGuid}, alias != "")
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return "", err
+	}
+	if alias == "" {
+		return resp.ShortLink, nil
 	}
-	req.Header.Set("Authorization", "Bearer "+b.Token)
 
-	resp, err := b.client.Do(req)
+	link, err := b.attachAlias(ctx, resp.Id, alias, domain)
+	if err != nil && created {
+		return "", b.discard(ctx, resp.Id, err)
+	}
+
+	return link, err
+}
+
+// attachAlias creates custom bitlink with given alias which points to bitlink, empty domain is replaced
+// with client default
+func (b *Bilty) attachAlias(ctx context.Context, bitlinkId string, alias string, domain string) (string, error) {
+	domain = cmp.Or(domain, b.domain, defaultDomain)
+	var custom CustomBitlinkResponse
+	err := b.do(ctx, http.MethodPost, customBitlinkUrl, CustomBitlinkRequest{
+		CustomBitlink: domain + "/" + alias,
+		BitlinkId:     bitlinkId,
+	}, &custom)
+	switch {
+	case isApiError(err, http.StatusConflict, msgAlreadyExists):
+		return "", fmt.Errorf("%w: %w", ErrAliasTaken, err)
+	case isApiError(err, http.StatusPaymentRequired, msgUpgradeRequired):
+		return "", fmt.Errorf("%w: %w", ErrUpgradeRequired, err)
+	case err != nil:
+		return "", err
+	}
+
+	return "https://" + custom.CustomBitlink, nil
+}
+
+// discard deletes bitlink which was created for request failed with err, so it isn't left orphaned
+//
+// Deletion isn't stopped by cancellation of ctx, it is likely the reason of failure
+func (b *Bilty) discard(ctx context.Context, bitlinkId string, err error) error {
+	if delErr := b.do(context.WithoutCancel(ctx), http.MethodDelete, bitlinksUrl+bitlinkId, nil, nil); delErr != nil {
+		return fmt.Errorf("%w, created bitlink %s is left: %v", err, bitlinkId, delErr)
+	}
+
+	return err
+}
+
+// CreateExpiringShortLink is CreateCustomShortLink which sets expiration of created bitlink,
+// zero expiresAt means bitlink never expires
+//
+// Expiration is set by update of created bitlink. Bitly returns existing bitlink for the same long url,
+// which may be already handed out as permanent, so expiration is set only when new bitlink is created
+//
+// Newly created bitlink is deleted when expiration or alias can't be set, see CreateCustomShortLink
+//
+// ErrLinkExists returned when bitlink of long url already exists in the group, nothing is changed then
+// ErrUpgradeRequired returned when expiration is disabled or current plan doesn't support it
+func (b *Bilty) CreateExpiringShortLink(longUrl string, alias string, domain string, groupGuid string, expiresAt time.Time) (string, error) {
+	return b.CreateExpiringShortLinkContext(context.Background(), longUrl, alias, domain, groupGuid, expiresAt)
+}
+
+// CreateExpiringShortLinkContext is CreateExpiringShortLink which stops waiting for API when ctx is done
+func (b *Bilty) CreateExpiringShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, groupGuid string, expiresAt time.Time) (string, error) {
+	if expiresAt.IsZero() {
+		return b.CreateCustomShortLinkContext(ctx, longUrl, alias, domain, groupGuid)
+	}
+	if !b.expiration {
+		return "", fmt.Errorf("%w: %v", ErrUpgradeRequired, "expiration of bitlinks is disabled")
+	}
+
+	resp, created, err := b.shorten(ctx, CreateLinkRequest{Link: longUrl, Domain: domain, GroupGuid: groupGuid}, true)
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		return "", fmt.Errorf("%w: %v", ErrLinkExists, "expiration can't be set to existing bitlink "+resp.Id)
+	}
+
+	expirationAt := expiresAt.UTC().Format(time.RFC3339)
+	_, err = b.UpdateBitlinkContext(ctx, resp.Id, UpdateBitlinkRequest{ExpirationAt: &expirationAt})
+	if isApiError(err, http.StatusPaymentRequired, msgUpgradeRequired) {
+		err = fmt.Errorf("%w: %w", ErrUpgradeRequired, err)
+	}
+	if err != nil {
+		// Bitlink without expiration must not be left, it could be handed out as permanent later
+		return "", b.discard(ctx, resp.Id, err)
+	}
+	if alias == "" {
+		return resp.ShortLink, nil
+	}
+
+	link, err := b.attachAlias(ctx, resp.Id, alias, domain)
+	if err != nil {
+		return "", b.discard(ctx, resp.Id, err)
+	}
+
+	return link, nil
+}
+
+// ExpandShortLink returns long URL the given bitlink points to
+//
+// Bitlink can be passed both with and without scheme, e.g. https://bit.ly/abc or bit.ly/abc
+//
+// ErrNotFound returned when bitlink doesn't exist
+func (b *Bilty) ExpandShortLink(shortUrl string) (string, error) {
+	return b.ExpandShortLinkContext(context.Background(), shortUrl)
+}
+
+// ExpandShortLinkContext is ExpandShortLink which stops waiting for API when ctx is done
+func (b *Bilty) ExpandShortLinkContext(ctx context.Context, shortUrl string) (string, error) {
+	var response ExpandLinkResponse
+	if err := b.do(ctx, http.MethodPost, expandUrl, ExpandLinkRequest{BitlinkId: bitlinkId(shortUrl)}, &response); err != nil {
+		return "", notFoundError(err)
+	}
+
+	return response.LongUrl, nil
+}
+
+// UpdateBitlink changes fields of the given bitlink which are set in request and returns updated bitlink
+//
+// ErrNotFound returned when bitlink doesn't exist
+func (b *Bilty) UpdateBitlink(shortUrl string, request UpdateBitlinkRequest) (Bitlink, error) {
+	return b.UpdateBitlinkContext(context.Background(), shortUrl, request)
+}
+
+// UpdateBitlinkContext is UpdateBitlink which stops waiting for API when ctx is done
+func (b *Bilty) UpdateBitlinkContext(ctx context.Context, shortUrl string, request UpdateBitlinkRequest) (Bitlink, error) {
+	var response Bitlink
+	if err := b.do(ctx, http.MethodPatch, bitlinksUrl+bitlinkId(shortUrl), request, &response); err != nil {
+		return Bitlink{}, notFoundError(err)
+	}
+
+	return response, nil
+}
+
+// UpdateLink changes title and tags of the given bitlink
+//
+// nil title or tags are kept unchanged, empty non-nil tags remove all tags of the bitlink
+func (b *Bilty) UpdateLink(shortUrl string, title *string, tags []string) error {
+	return b.UpdateLinkContext(context.Background(), shortUrl, title, tags)
+}
+
+// UpdateLinkContext is UpdateLink which stops waiting for API when ctx is done
+func (b *Bilty) UpdateLinkContext(ctx context.Context, shortUrl string, title *string, tags []string) error {
+	return b.EditLinkContext(ctx, shortUrl, "", title, tags)
+}
+
+// EditLink changes long url, title and tags of the given bitlink with single update,
+// so either all of them are changed or none
+//
+// Empty long url and nil title or tags are kept unchanged, empty non-nil tags remove all tags of the bitlink.
+// ErrUpgradeRequired returned when current plan doesn't support changing long url
+func (b *Bilty) EditLink(shortUrl string, longUrl string, title *string, tags []string) error {
+	return b.EditLinkContext(context.Background(), shortUrl, longUrl, title, tags)
+}
+
+// EditLinkContext is EditLink which stops waiting for API when ctx is done
+func (b *Bilty) EditLinkContext(ctx context.Context, shortUrl string, longUrl string, title *string, tags []string) error {
+	request := UpdateBitlinkRequest{Title: title}
+	if longUrl != "" {
+		request.LongUrl = &longUrl
+	}
+	if tags != nil {
+		request.Tags = &tags
+	}
+
+	_, err := b.UpdateBitlinkContext(ctx, shortUrl, request)
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusPaymentRequired {
+		return fmt.Errorf("%w: %w", ErrUpgradeRequired, err)
+	}
+	return err
+}
+
+// ArchiveLink hides the given bitlink from link lists, archived bitlink keeps redirecting
+func (b *Bilty) ArchiveLink(shortUrl string) error {
+	return b.ArchiveLinkContext(context.Background(), shortUrl)
+}
+
+// ArchiveLinkContext is ArchiveLink which stops waiting for API when ctx is done
+func (b *Bilty) ArchiveLinkContext(ctx context.Context, shortUrl string) error {
+	archived := true
+	_, err := b.UpdateBitlinkContext(ctx, shortUrl, UpdateBitlinkRequest{Archived: &archived})
+	return err
+}
+
+// DeleteLink deletes the given bitlink, API allows deleting only bitlinks which were never edited
+//
+// ErrNotFound returned when bitlink doesn't exist
+func (b *Bilty) DeleteLink(shortUrl string) error {
+	return b.DeleteLinkContext(context.Background(), shortUrl)
+}
+
+// DeleteLinkContext is DeleteLink which stops waiting for API when ctx is done
+func (b *Bilty) DeleteLinkContext(ctx context.Context, shortUrl string) error {
+	if err := b.do(ctx, http.MethodDelete, bitlinksUrl+bitlinkId(shortUrl), nil, nil); err != nil {
+		return notFoundError(err)
+	}
+
+	return nil
+}
+
+// ClicksSummary returns total amount of clicks of the given bitlink
+// over window of given amount of days which ends at until
+//
+// If days is not positive, clicks are counted for all time
+func (b *Bilty) ClicksSummary(shortUrl string, days int, until time.Time) (int, error) {
+	return b.ClicksSummaryContext(context.Background(), shortUrl, days, until)
+}
+
+// ClicksSummaryContext is ClicksSummary which stops waiting for API when ctx is done
+func (b *Bilty) ClicksSummaryContext(ctx context.Context, shortUrl string, days int, until time.Time) (int, error) {
+	var response ClicksSummaryResponse
+	if err := b.do(ctx, http.MethodGet, clicksUrl(shortUrl, clicksSummaryPath, days, until), nil, &response); err != nil {
+		return 0, notFoundError(err)
+	}
+
+	return response.TotalClicks, nil
+}
+
+// Clicks returns per-day clicks of the given bitlink
+// over window of given amount of days which ends at until
+//
+// If days is not positive, clicks are returned for all time
+func (b *Bilty) Clicks(shortUrl string, days int, until time.Time) ([]LinkClicks, error) {
+	return b.ClicksContext(context.Background(), shortUrl, days, until)
+}
+
+// ClicksContext is Clicks which stops waiting for API when ctx is done
+func (b *Bilty) ClicksContext(ctx context.Context, shortUrl string, days int, until time.Time) ([]LinkClicks, error) {
+	var response ClicksResponse
+	if err := b.do(ctx, http.MethodGet, clicksUrl(shortUrl, clicksPath, days, until), nil, &response); err != nil {
+		return nil, notFoundError(err)
+	}
+
+	return response.LinkClicks, nil
+}
+
+// GetLinkStats returns total clicks and clicks per day(in YYYY-MM-DD format)
+// of the given bitlink over window of given amount of days which ends at until
+//
+// ErrNotFound returned when bitlink doesn't exist
+func (b *Bilty) GetLinkStats(shortUrl string, days int, until time.Time) (int, map[string]int, error) {
+	return b.GetLinkStatsContext(context.Background(), shortUrl, days, until)
+}
+
+// GetLinkStatsContext is GetLinkStats which stops waiting for API when ctx is done
+func (b *Bilty) GetLinkStatsContext(ctx context.Context, shortUrl string, days int, until time.Time) (int, map[string]int, error) {
+	total, err := b.ClicksSummaryContext(ctx, shortUrl, days, until)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	clicks, err := b.ClicksContext(ctx, shortUrl, days, until)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	daily := make(map[string]int, len(clicks))
+	for _, c := range clicks {
+		date, err := time.Parse(unitReferenceLayout, c.Date)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+		daily[date.Format(dateLayout)] += c.Clicks
+	}
+
+	return total, daily, nil
+}
+
+func clicksUrl(shortUrl string, path string, days int, until time.Time) string {
+	if days <= 0 {
+		days = -1
+	}
+
+	query := url.Values{}
+	query.Set("unit", "day")
+	query.Set("units", strconv.Itoa(days))
+	query.Set("unit_reference", until.Format(unitReferenceLayout))
+
+	return bitlinksUrl + bitlinkId(shortUrl) + path + "?" + query.Encode()
+}
+
+// isApiError reports whether err is API error with given status code and message
+func isApiError(err error, statusCode int, message string) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode && apiErr.Message == message
+}
+
+// notFoundError marks API error with 404 status as ErrNotFound
+func notFoundError(err error) error {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// bitlinkId converts bitlink url to the id used by API(domain and back-half without scheme)
+func bitlinkId(shortUrl string) string {
+	id := strings.TrimPrefix(shortUrl, "https://")
+	id = strings.TrimPrefix(id, "http://")
+	return strings.TrimSuffix(id, "/")
+}
+
+// Groups returns groups available to the token
+func (b *Bilty) Groups() ([]Group, error) {
+	return b.GroupsContext(context.Background())
+}
+
+// GroupsContext is Groups which stops waiting for API when ctx is done
+func (b *Bilty) GroupsContext(ctx context.Context) ([]Group, error) {
+	var response GroupsResponse
+	if err := b.do(ctx, http.MethodGet, groupsUrl, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Groups, nil
+}
+
+// BrandedDomains returns branded short domains available to the token
+func (b *Bilty) BrandedDomains() ([]string, error) {
+	return b.BrandedDomainsContext(context.Background())
+}
+
+// BrandedDomainsContext is BrandedDomains which stops waiting for API when ctx is done
+func (b *Bilty) BrandedDomainsContext(ctx context.Context) ([]string, error) {
+	var response BrandedDomainsResponse
+	if err := b.do(ctx, http.MethodGet, bsdsUrl, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Domains, nil
+}
+
+// ListGroups returns names of groups available to the token by their guids
+func (b *Bilty) ListGroups() (map[string]string, error) {
+	return b.ListGroupsContext(context.Background())
+}
+
+// ListGroupsContext is ListGroups which stops waiting for API when ctx is done
+func (b *Bilty) ListGroupsContext(ctx context.Context) (map[string]string, error) {
+	groups, err := b.GroupsContext(ctx)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return nil, err
+	}
+
+	names := make(map[string]string, len(groups))
+	for _, g := range groups {
+		names[g.Guid] = g.Name
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return names, nil
+}
+
+// ListDomains returns domains available for shortening, bit.ly and branded short domains of the token
+func (b *Bilty) ListDomains() ([]string, error) {
+	return b.ListDomainsContext(context.Background())
+}
+
+// ListDomainsContext is ListDomains which stops waiting for API when ctx is done
+func (b *Bilty) ListDomainsContext(ctx context.Context) ([]string, error) {
+	branded, err := b.BrandedDomainsContext(ctx)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return nil, err
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		var apiError ErrorMessage
-		if err := json.Unmarshal(body, &apiError); err != nil {
-			return "", fmt.Errorf("%w: %v", ErrInternal, err)
+	return append([]string{defaultDomain}, branded...), nil
+}
+
+// shorten creates bitlink, empty domain and group of request are replaced with client defaults
+//
+// created reports whether new bitlink was created, Bitly responds 200 instead of 201 with existing bitlink
+// of the same long url. Repeated attempt gets 200 for bitlink created by the lost one, so withCreated disables
+// retries after attempt possibly reached API, callers which depend on created must set it
+func (b *Bilty) shorten(ctx context.Context, request CreateLinkRequest, withCreated bool) (response CreateLinkResponse, created bool, err error) {
+	request.Domain = cmp.Or(request.Domain, b.domain)
+	request.GroupGuid = cmp.Or(request.GroupGuid, b.groupGuid)
+
+	statusCode, err := b.doStatus(ctx, http.MethodPost, shortenUrl, request, &response, !withCreated)
+	if err != nil {
+		return CreateLinkResponse{}, false, err
+	}
+
+	return response, statusCode == http.StatusCreated, nil
+}
+
+// do makes authorized request to given API path with json encoded request body(if not nil)
+// and decodes successful response to dest(if not nil)
+// Every attempt waits for rate limiter, failed attempts are retried according to retryDelay
+//
+// *ApiError returned when API responds with unsuccessful status
+// Error of ctx is returned when ctx is done before response is received
+func (b *Bilty) do(ctx context.Context, method string, path string, request any, dest any) error {
+	_, err := b.doStatus(ctx, method, path, request, dest, idempotent(method, path))
+	return err
+}
+
+// doStatus is do which returns status code of successful response,
+// attempts which possibly reached API are repeated only if request is repeatable
+func (b *Bilty) doStatus(ctx context.Context, method string, path string, request any, dest any, repeatable bool) (int, error) {
+	var reqBody []byte
+	if request != nil {
+		bts, err := json.Marshal(request)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+		reqBody = bts
+	}
+
+	var resp *http.Response
+	var body []byte
+	for attempt := 0; ; attempt++ {
+		if err := b.wait(ctx, b.limiter.reserve(time.Now())); err != nil {
+			return 0, err
 		}
 
-		return "", fmt.Errorf("%w: %v", ErrApiError, apiError.Message)
+		var err error
+		resp, body, err = b.send(ctx, method, path, reqBody)
+		if resp != nil {
+			b.observeRateLimit(resp.Header)
+		}
+
+		delay, retry := b.retryDelay(repeatable, attempt, resp, err)
+		if !retry || ctx.Err() != nil {
+			if err != nil {
+				return 0, err
+			}
+			break
+		}
+		if err := b.wait(ctx, delay); err != nil {
+			return 0, err
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &ApiError{StatusCode: resp.StatusCode}
+		var message ErrorMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			// Gateways may respond with non json body, status is still meaningful
+			apiErr.Message = http.StatusText(resp.StatusCode)
+			return 0, apiErr
+		}
+		apiErr.Message = message.Message
+		apiErr.Description = message.Description
+
+		return 0, apiErr
+	}
+
+	if dest != nil {
+		if err := json.Unmarshal(body, dest); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// send makes single attempt of the request and returns response with its read body
+func (b *Bilty) send(ctx context.Context, method string, path string, reqBody []byte) (*http.Response, []byte, error) {
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
 	}
 
-	var response CreateLinkResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+	req, err := http.NewRequestWithContext(ctx, method, cmp.Or(b.baseUrl, defaultBaseUrl)+path, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	for key, value := range b.headers {
+		req.Header.Set(key, value)
+	}
+	if b.userAgent != "" {
+		req.Header.Set("User-Agent", b.userAgent)
+	}
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		// Error chain is kept, so callers can recognize cancellation of ctx
+		return nil, nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
-	return response.ShortLink, nil
+	return resp, respBody, nil
 }
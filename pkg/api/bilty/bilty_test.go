@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 )
 
 type roundTripFunc func(r *http.Request) (*http.Response, error)
@@ -38,6 +39,39 @@ func newClientMock(t *testing.T, statusCode int, path string, response any) *Bil
 	}
 }
 
+type mockResponse struct {
+	statusCode int
+	body       any
+}
+
+// newRoutesMock creates client which responds with given response for every requested path,
+// routes can be bound to method as well, e.g. "DELETE /v4/bitlinks/bit.ly/abc", such routes take precedence
+func newRoutesMock(t *testing.T, routes map[string]mockResponse) *Bilty {
+	return &Bilty{
+		client: &http.Client{
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				route, ok := routes[r.Method+" "+r.URL.Path]
+				if !ok {
+					route, ok = routes[r.URL.Path]
+				}
+				if !ok {
+					assert.Fail(t, "Unexpected path", r.URL.Path)
+					route = mockResponse{statusCode: http.StatusNotFound, body: ErrorMessage{Message: "NOT_FOUND"}}
+				}
+
+				respBody, err := json.Marshal(route.body)
+				if err != nil {
+					assert.Fail(t, "Cannot read bytes")
+				}
+				return &http.Response{
+					StatusCode: route.statusCode,
+					Body:       io.NopCloser(bytes.NewReader(respBody)),
+				}, nil
+			}),
+		},
+	}
+}
+
 func TestCreateShortLink_TestCases(t *testing.T) {
 
 	tc := []struct {
@@ -86,6 +120,514 @@ func TestCreateShortLink_TestCases(t *testing.T) {
 	}
 }
 
+func TestCreateCustomShortLink_TestCases(t *testing.T) {
+	shortened := mockResponse{
+		statusCode: http.StatusCreated,
+		body:       CreateLinkResponse{Id: "bit.ly/abc", ShortLink: "https://bit.ly/abc"},
+	}
+	deleteBitlink := http.MethodDelete + " " + bitlinksUrl + "bit.ly/abc"
+
+	tc := []struct {
+		name   string
+		alias  string
+		domain string
+		routes map[string]mockResponse
+
+		want       string
+		wantErr    error
+		notWantErr error
+		wantErrMsg string
+	}{
+		{
+			name: "ok, without alias",
+			routes: map[string]mockResponse{
+				shortenUrl: shortened,
+			},
+			want: "https://bit.ly/abc",
+		},
+		{
+			name:   "ok, with alias",
+			alias:  "campaign",
+			domain: "brand.co",
+			routes: map[string]mockResponse{
+				shortenUrl:       shortened,
+				customBitlinkUrl: {statusCode: http.StatusOK, body: CustomBitlinkResponse{CustomBitlink: "brand.co/campaign"}},
+			},
+			want: "https://brand.co/campaign",
+		},
+		{
+			name:  "alias taken, created bitlink is deleted",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl:       shortened,
+				customBitlinkUrl: {statusCode: http.StatusConflict, body: ErrorMessage{Message: "ALREADY_EXISTS"}},
+				deleteBitlink:    {statusCode: http.StatusOK, body: struct{}{}},
+			},
+			wantErr: ErrAliasTaken,
+		},
+		{
+			name:  "alias taken, existing bitlink is kept",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl:       {statusCode: http.StatusOK, body: CreateLinkResponse{Id: "bit.ly/abc", ShortLink: "https://bit.ly/abc"}},
+				customBitlinkUrl: {statusCode: http.StatusConflict, body: ErrorMessage{Message: "ALREADY_EXISTS"}},
+			},
+			wantErr: ErrAliasTaken,
+		},
+		{
+			name:  "upgrade required",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl:       shortened,
+				customBitlinkUrl: {statusCode: http.StatusPaymentRequired, body: ErrorMessage{Message: "UPGRADE_REQUIRED"}},
+				deleteBitlink:    {statusCode: http.StatusOK, body: struct{}{}},
+			},
+			wantErr: ErrUpgradeRequired,
+		},
+		{
+			name:  "undocumented message isn't alias taken",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl:       shortened,
+				customBitlinkUrl: {statusCode: http.StatusBadRequest, body: ErrorMessage{Message: "ALREADY_A_BITLINK"}},
+				deleteBitlink:    {statusCode: http.StatusOK, body: struct{}{}},
+			},
+			wantErr:    ErrApiError,
+			notWantErr: ErrAliasTaken,
+		},
+		{
+			name:  "created bitlink is left when it can't be deleted",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl:       shortened,
+				customBitlinkUrl: {statusCode: http.StatusConflict, body: ErrorMessage{Message: "ALREADY_EXISTS"}},
+				deleteBitlink:    {statusCode: http.StatusForbidden, body: ErrorMessage{Message: "FORBIDDEN"}},
+			},
+			wantErr:    ErrAliasTaken,
+			wantErrMsg: "created bitlink bit.ly/abc is left",
+		},
+		{
+			name:  "shorten failed",
+			alias: "campaign",
+			routes: map[string]mockResponse{
+				shortenUrl: {statusCode: http.StatusBadRequest, body: ErrorMessage{Message: "INVALID_ARG_LONG_URL"}},
+			},
+			wantErr: ErrApiError,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			bil := newRoutesMock(t, tt.routes)
+
+			got, err := bil.CreateCustomShortLink("https://www.google.com/", tt.alias, tt.domain, "")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				if tt.notWantErr != nil {
+					assert.NotErrorIs(t, err, tt.notWantErr)
+				}
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateExpiringShortLink_TestCases(t *testing.T) {
+	shortened := mockResponse{
+		statusCode: http.StatusCreated,
+		body:       CreateLinkResponse{Id: "bit.ly/abc", ShortLink: "https://bit.ly/abc"},
+	}
+	expiresAt := time.Now().Add(time.Hour)
+	bitlinkPath := bitlinksUrl + "bit.ly/abc"
+
+	tc := []struct {
+		name       string
+		expiration bool
+		expiresAt  time.Time
+		routes     map[string]mockResponse
+
+		want    string
+		wantErr error
+	}{
+		{
+			name:       "ok",
+			expiration: true,
+			expiresAt:  expiresAt,
+			routes: map[string]mockResponse{
+				shortenUrl:                 shortened,
+				bitlinksUrl + "bit.ly/abc": {statusCode: http.StatusOK, body: Bitlink{Id: "bit.ly/abc"}},
+			},
+			want: "https://bit.ly/abc",
+		},
+		{
+			name: "ok, never expires",
+			routes: map[string]mockResponse{
+				shortenUrl: shortened,
+			},
+			want: "https://bit.ly/abc",
+		},
+		{
+			name:      "expiration disabled",
+			expiresAt: expiresAt,
+			routes:    map[string]mockResponse{},
+			wantErr:   ErrUpgradeRequired,
+		},
+		{
+			name:       "existing bitlink isn't changed",
+			expiration: true,
+			expiresAt:  expiresAt,
+			routes: map[string]mockResponse{
+				shortenUrl: {statusCode: http.StatusOK, body: CreateLinkResponse{Id: "bit.ly/abc", ShortLink: "https://bit.ly/abc"}},
+			},
+			wantErr: ErrLinkExists,
+		},
+		{
+			name:       "not supported by plan",
+			expiration: true,
+			expiresAt:  expiresAt,
+			routes: map[string]mockResponse{
+				shortenUrl:                            shortened,
+				http.MethodPatch + " " + bitlinkPath:  {statusCode: http.StatusPaymentRequired, body: ErrorMessage{Message: "UPGRADE_REQUIRED"}},
+				http.MethodDelete + " " + bitlinkPath: {statusCode: http.StatusOK, body: struct{}{}},
+			},
+			wantErr: ErrUpgradeRequired,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			bil := newRoutesMock(t, tt.routes)
+			bil.expiration = tt.expiration
+
+			got, err := bil.CreateExpiringShortLink("https://www.google.com/", "", "", "", tt.expiresAt)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandShortLink_TestCases(t *testing.T) {
+	tc := []struct {
+		name     string
+		shortUrl string
+		response mockResponse
+
+		want    string
+		wantErr error
+	}{
+		{
+			name:     "ok",
+			shortUrl: "https://bit.ly/abc",
+			response: mockResponse{statusCode: http.StatusOK, body: ExpandLinkResponse{Id: "bit.ly/abc", LongUrl: "https://www.google.com/"}},
+			want:     "https://www.google.com/",
+		},
+		{
+			name:     "not found",
+			shortUrl: "bit.ly/missing",
+			response: mockResponse{statusCode: http.StatusNotFound, body: ErrorMessage{Message: "NOT_FOUND"}},
+			wantErr:  ErrNotFound,
+		},
+		{
+			name:     "some error",
+			shortUrl: "bit.ly/abc",
+			response: mockResponse{statusCode: http.StatusForbidden, body: ErrorMessage{Message: "FORBIDDEN"}},
+			wantErr:  ErrApiError,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			bil := newRoutesMock(t, map[string]mockResponse{expandUrl: tt.response})
+
+			got, err := bil.ExpandShortLink(tt.shortUrl)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetLinkStats_TestCases(t *testing.T) {
+	summaryPath := bitlinksUrl + "bit.ly/abc" + clicksSummaryPath
+	clicksPath := bitlinksUrl + "bit.ly/abc" + clicksPath
+
+	tc := []struct {
+		name   string
+		routes map[string]mockResponse
+
+		wantTotal int
+		wantDaily map[string]int
+		wantErr   error
+	}{
+		{
+			name: "ok",
+			routes: map[string]mockResponse{
+				summaryPath: {statusCode: http.StatusOK, body: ClicksSummaryResponse{TotalClicks: 5, Unit: "day", Units: 2}},
+				clicksPath: {statusCode: http.StatusOK, body: ClicksResponse{LinkClicks: []LinkClicks{
+					{Clicks: 3, Date: "2024-03-02T00:00:00+0000"},
+					{Clicks: 2, Date: "2024-03-01T00:00:00+0000"},
+				}}},
+			},
+			wantTotal: 5,
+			wantDaily: map[string]int{"2024-03-02": 3, "2024-03-01": 2},
+		},
+		{
+			name: "not found",
+			routes: map[string]mockResponse{
+				summaryPath: {statusCode: http.StatusNotFound, body: ErrorMessage{Message: "NOT_FOUND"}},
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "bad date",
+			routes: map[string]mockResponse{
+				summaryPath: {statusCode: http.StatusOK, body: ClicksSummaryResponse{TotalClicks: 1}},
+				clicksPath:  {statusCode: http.StatusOK, body: ClicksResponse{LinkClicks: []LinkClicks{{Clicks: 1, Date: "yesterday"}}}},
+			},
+			wantErr: ErrInternal,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			bil := newRoutesMock(t, tt.routes)
+
+			total, daily, err := bil.GetLinkStats("https://bit.ly/abc", 2, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTotal, total)
+				assert.Equal(t, tt.wantDaily, daily)
+			}
+		})
+	}
+}
+
+func TestApiError_TestCases(t *testing.T) {
+	tc := []struct {
+		name       string
+		statusCode int
+		body       any
+		want       ApiError
+	}{
+		{
+			name:       "with description",
+			statusCode: http.StatusBadRequest,
+			body:       ErrorMessage{Message: "INVALID_ARG_LONG_URL", Description: "The value provided is invalid."},
+			want:       ApiError{StatusCode: http.StatusBadRequest, Message: "INVALID_ARG_LONG_URL", Description: "The value provided is invalid."},
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       ErrorMessage{Message: "RATE_LIMIT_EXCEEDED"},
+			want:       ApiError{StatusCode: http.StatusTooManyRequests, Message: "RATE_LIMIT_EXCEEDED"},
+		},
+		{
+			name:       "non json body",
+			statusCode: http.StatusBadGateway,
+			body:       "<html>bad gateway</html>",
+			want:       ApiError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			bil := &Bilty{
+				client: &http.Client{
+					Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+						body, ok := tt.body.(string)
+						if !ok {
+							bts, err := json.Marshal(tt.body)
+							require.NoError(t, err)
+							body = string(bts)
+						}
+						return &http.Response{
+							StatusCode: tt.statusCode,
+							Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+						}, nil
+					}),
+				},
+			}
+
+			_, err := bil.CreateShortLink("https://www.google.com/")
+			assert.ErrorIs(t, err, ErrApiError)
+
+			var apiErr *ApiError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.want, *apiErr)
+		})
+	}
+}
+
+func TestShortenDefaults_TestCases(t *testing.T) {
+	tc := []struct {
+		name   string
+		opts   []Option
+		domain string
+		group  string
+		want   CreateLinkRequest
+	}{
+		{
+			name: "no defaults",
+			want: CreateLinkRequest{Link: "https://www.google.com/"},
+		},
+		{
+			name: "defaults",
+			opts: []Option{WithDomain("brand.co"), WithGroupGuid("Ba1")},
+			want: CreateLinkRequest{Link: "https://www.google.com/", Domain: "brand.co", GroupGuid: "Ba1"},
+		},
+		{
+			name:   "overridden defaults",
+			opts:   []Option{WithDomain("brand.co"), WithGroupGuid("Ba1")},
+			domain: "other.co",
+			group:  "Bb2",
+			want:   CreateLinkRequest{Link: "https://www.google.com/", Domain: "other.co", GroupGuid: "Bb2"},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CreateLinkRequest
+			client := &http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					assert.Equal(t, shortenUrl, r.URL.Path)
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+					respBody, err := json.Marshal(CreateLinkResponse{Id: "bit.ly/abc", ShortLink: "https://bit.ly/abc"})
+					require.NoError(t, err)
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewReader(respBody)),
+					}, nil
+				}),
+			}
+			bil := NewBilty("token", client, tt.opts...)
+
+			_, err := bil.CreateCustomShortLink("https://www.google.com/", "", tt.domain, tt.group)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListGroupsAndDomains(t *testing.T) {
+	bil := newRoutesMock(t, map[string]mockResponse{
+		groupsUrl: {statusCode: http.StatusOK, body: GroupsResponse{Groups: []Group{
+			{Guid: "Ba1", Name: "marketing"},
+			{Guid: "Bb2", Name: "support"},
+		}}},
+		bsdsUrl: {statusCode: http.StatusOK, body: BrandedDomainsResponse{Domains: []string{"brand.co"}}},
+	})
+
+	groups, err := bil.ListGroups()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Ba1": "marketing", "Bb2": "support"}, groups)
+
+	domains, err := bil.ListDomains()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bit.ly", "brand.co"}, domains)
+}
+
+func TestManageBitlink_TestCases(t *testing.T) {
+	title := "Campaign"
+
+	tc := []struct {
+		name       string
+		call       func(b *Bilty) error
+		statusCode int
+		wantMethod string
+		wantBody   string
+		wantErr    error
+	}{
+		{
+			name:       "update title and tags",
+			call:       func(b *Bilty) error { return b.UpdateLink("https://bit.ly/abc", &title, []string{"a"}) },
+			statusCode: http.StatusOK,
+			wantMethod: http.MethodPatch,
+			wantBody:   `{"title":"Campaign","tags":["a"]}`,
+		},
+		{
+			name:       "clear tags",
+			call:       func(b *Bilty) error { return b.UpdateLink("bit.ly/abc", nil, []string{}) },
+			statusCode: http.StatusOK,
+			wantMethod: http.MethodPatch,
+			wantBody:   `{"tags":[]}`,
+		},
+		{
+			name:       "archive",
+			call:       func(b *Bilty) error { return b.ArchiveLink("https://bit.ly/abc") },
+			statusCode: http.StatusOK,
+			wantMethod: http.MethodPatch,
+			wantBody:   `{"archived":true}`,
+		},
+		{
+			name: "expiration",
+			call: func(b *Bilty) error {
+				expirationAt := "2030-01-02T03:04:05Z"
+				_, err := b.UpdateBitlink("https://bit.ly/abc", UpdateBitlinkRequest{ExpirationAt: &expirationAt})
+				return err
+			},
+			statusCode: http.StatusOK,
+			wantMethod: http.MethodPatch,
+			wantBody:   `{"expiration_at":"2030-01-02T03:04:05Z"}`,
+		},
+		{
+			name:       "delete",
+			call:       func(b *Bilty) error { return b.DeleteLink("https://bit.ly/abc") },
+			statusCode: http.StatusOK,
+			wantMethod: http.MethodDelete,
+		},
+		{
+			name:       "not found",
+			call:       func(b *Bilty) error { return b.DeleteLink("https://bit.ly/abc") },
+			statusCode: http.StatusNotFound,
+			wantMethod: http.MethodDelete,
+			wantErr:    ErrNotFound,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					assert.Equal(t, tt.wantMethod, r.Method)
+					assert.Equal(t, bitlinksUrl+"bit.ly/abc", r.URL.Path)
+					if tt.wantBody != "" {
+						body, err := io.ReadAll(r.Body)
+						require.NoError(t, err)
+						assert.JSONEq(t, tt.wantBody, string(body))
+					}
+
+					respBody, err := json.Marshal(Bitlink{Id: "bit.ly/abc"})
+					require.NoError(t, err)
+					return &http.Response{
+						StatusCode: tt.statusCode,
+						Body:       io.NopCloser(bytes.NewReader(respBody)),
+					}, nil
+				}),
+			}
+
+			err := tt.call(NewBilty("token", client))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 // API test
 func TestBitly_TestCases(t *testing.T) {
 
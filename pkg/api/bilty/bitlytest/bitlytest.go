@@ -0,0 +1,440 @@
+// Package bitlytest provides in-process emulator of Bitly API for offline tests
+//
+// Emulator keeps bitlinks in memory and implements shorten, expand, bitlink update and delete
+// and clicks endpoints. Failures of the API, such as rate limits and outages, are scripted with Fail
+package bitlytest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultToken  = "test-token"
+	DefaultDomain = "bit.ly"
+	DefaultGroup  = "Bk1a2b3c4d5"
+
+	unitReferenceLayout = "2006-01-02T15:04:05-0700"
+	dateLayout          = "2006-01-02"
+)
+
+// Bitlink is state of bitlink kept by emulator
+type Bitlink struct {
+	Id           string
+	LongUrl      string
+	GroupGuid    string
+	Title        string
+	Tags         []string
+	Archived     bool
+	ExpirationAt string
+	// Clicks is amount of clicks per day(UTC) in YYYY-MM-DD format
+	Clicks map[string]int
+}
+
+// Failure is scripted error response of the API
+//
+// Empty Method and Path match any request, Path is matched as prefix of request path.
+// Not positive Times means every matching request fails until failures are reset
+type Failure struct {
+	Method string
+	Path   string
+	Times  int
+
+	StatusCode  int
+	Message     string
+	Description string
+	// Header is added to response, e.g. Retry-After
+	Header http.Header
+	// Body replaces json error body, e.g. with html page of gateway
+	Body string
+}
+
+// Server is Bitly API emulator, URL of the embedded httptest server is base url of the API
+type Server struct {
+	*httptest.Server
+
+	token string
+
+	mu       sync.Mutex
+	links    map[string]*Bitlink
+	failures []*Failure
+	requests []string
+	seq      int
+}
+
+// Option configures Server on creation
+type Option func(*Server)
+
+// WithToken sets token which must be sent in Authorization header, empty token disables the check
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// NewServer starts emulator, caller must Close it when finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		token: DefaultToken,
+		links: make(map[string]*Bitlink),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v4/shorten", s.shorten)
+	mux.HandleFunc("POST /v4/expand", s.expand)
+	mux.HandleFunc("GET /v4/bitlinks/{id...}", s.get)
+	mux.HandleFunc("PATCH /v4/bitlinks/{id...}", s.update)
+	mux.HandleFunc("DELETE /v4/bitlinks/{id...}", s.delete)
+	s.Server = httptest.NewServer(s.middleware(mux))
+
+	return s
+}
+
+// Fail scripts failure of matching requests, failures are checked in order they were added
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &f)
+}
+
+// ResetFailures removes all scripted failures
+func (s *Server) ResetFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = nil
+}
+
+// Requests returns method and path of every received request in order, e.g. "POST /v4/shorten"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// Bitlink returns copy of bitlink with given id, e.g. bit.ly/abc
+func (s *Server) Bitlink(id string) (Bitlink, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[id]
+	if !ok {
+		return Bitlink{}, false
+	}
+	return clone(link), true
+}
+
+// Click records n clicks of bitlink with given id at given moment
+func (s *Server) Click(id string, at time.Time, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[id]
+	if !ok {
+		return fmt.Errorf("bitlink %s not found", id)
+	}
+	link.Clicks[at.UTC().Format(dateLayout)] += n
+
+	return nil
+}
+
+// middleware records requests, responds with scripted failures and checks authorization
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		f := s.failure(r)
+		s.mu.Unlock()
+
+		if f != nil {
+			for key, values := range f.Header {
+				w.Header()[key] = values
+			}
+			if f.Body != "" {
+				w.WriteHeader(f.StatusCode)
+				_, _ = w.Write([]byte(f.Body))
+				return
+			}
+			writeError(w, f.StatusCode, f.Message, f.Description)
+			return
+		}
+
+		if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+			writeError(w, http.StatusForbidden, "FORBIDDEN", "")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// failure returns copy of the first scripted failure matching request and uses up one of its times
+func (s *Server) failure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method || !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		matched := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = slices.Delete(s.failures, i, i+1)
+			}
+		}
+		return &matched
+	}
+
+	return nil
+}
+
+func (s *Server) shorten(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		LongUrl   string `json:"long_url"`
+		Domain    string `json:"domain"`
+		GroupGuid string `json:"group_guid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if u, err := url.Parse(req.LongUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_ARG_LONG_URL", "")
+		return
+	}
+	domain := cmp.Or(req.Domain, DefaultDomain)
+	group := cmp.Or(req.GroupGuid, DefaultGroup)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Bitly returns existing bitlink for the same long url in the same group and domain
+	for _, link := range s.links {
+		if link.LongUrl == req.LongUrl && link.GroupGuid == group && strings.HasPrefix(link.Id, domain+"/") {
+			writeJSON(w, http.StatusOK, bitlinkResponse(link))
+			return
+		}
+	}
+
+	s.seq++
+	link := &Bitlink{
+		Id:        domain + "/" + strconv.FormatInt(int64(1000000+s.seq), 36),
+		LongUrl:   req.LongUrl,
+		GroupGuid: group,
+		Tags:      []string{},
+		Clicks:    make(map[string]int),
+	}
+	s.links[link.Id] = link
+	writeJSON(w, http.StatusCreated, bitlinkResponse(link))
+}
+
+func (s *Server) expand(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		BitlinkId string `json:"bitlink_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[req.BitlinkId]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": link.Id, "long_url": link.LongUrl})
+}
+
+// get serves bitlink and its clicks, id of bitlink contains slash, so clicks paths are matched by suffix
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch {
+	case strings.HasSuffix(id, "/clicks/summary"):
+		s.clicks(w, r, strings.TrimSuffix(id, "/clicks/summary"), true)
+		return
+	case strings.HasSuffix(id, "/clicks"):
+		s.clicks(w, r, strings.TrimSuffix(id, "/clicks"), false)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, bitlinkResponse(link))
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title        *string   `json:"title"`
+		Tags         *[]string `json:"tags"`
+		Archived     *bool     `json:"archived"`
+		ExpirationAt *string   `json:"expiration_at"`
+		LongUrl      *string   `json:"long_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if req.ExpirationAt != nil {
+		if _, err := time.Parse(time.RFC3339, *req.ExpirationAt); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARG_EXPIRATION_AT", "")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "")
+		return
+	}
+	if req.Title != nil {
+		link.Title = *req.Title
+	}
+	if req.Tags != nil {
+		link.Tags = slices.Clone(*req.Tags)
+	}
+	if req.Archived != nil {
+		link.Archived = *req.Archived
+	}
+	if req.ExpirationAt != nil {
+		link.ExpirationAt = *req.ExpirationAt
+	}
+	if req.LongUrl != nil {
+		link.LongUrl = *req.LongUrl
+	}
+	writeJSON(w, http.StatusOK, bitlinkResponse(link))
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.links[id]; !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "")
+		return
+	}
+	delete(s.links, id)
+	writeJSON(w, http.StatusOK, map[string]any{"links_deleted": []map[string]string{{"id": id}}})
+}
+
+// clicks serves per-day clicks or their summary over window of units days which ends at unit_reference
+//
+// Negative units mean all time, only day unit is supported
+func (s *Server) clicks(w http.ResponseWriter, r *http.Request, id string, summary bool) {
+	query := r.URL.Query()
+	if unit := query.Get("unit"); unit != "" && unit != "day" {
+		writeError(w, http.StatusBadRequest, "INVALID_ARG_UNIT", "")
+		return
+	}
+	units, err := strconv.Atoi(cmp.Or(query.Get("units"), "-1"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARG_UNITS", "")
+		return
+	}
+	reference := time.Now()
+	if raw := query.Get("unit_reference"); raw != "" {
+		if reference, err = time.Parse(unitReferenceLayout, raw); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARG_UNIT_REFERENCE", "")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	link, ok := s.links[id]
+	var clicks map[string]int
+	if ok {
+		clicks = maps.Clone(link.Clicks)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "")
+		return
+	}
+
+	// Days of the window in descending order, as Bitly returns them
+	var days []string
+	if units < 0 {
+		for day := range clicks {
+			days = append(days, day)
+		}
+		slices.Sort(days)
+		slices.Reverse(days)
+	} else {
+		end := reference.UTC()
+		for i := 0; i < units; i++ {
+			days = append(days, end.AddDate(0, 0, -i).Format(dateLayout))
+		}
+	}
+
+	total := 0
+	linkClicks := make([]map[string]any, 0, len(days))
+	for _, day := range days {
+		total += clicks[day]
+		date, _ := time.Parse(dateLayout, day)
+		linkClicks = append(linkClicks, map[string]any{"clicks": clicks[day], "date": date.Format(unitReferenceLayout)})
+	}
+
+	if summary {
+		writeJSON(w, http.StatusOK, map[string]any{"total_clicks": total, "unit": "day", "units": units})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"link_clicks": linkClicks, "unit": "day", "units": units})
+}
+
+func bitlinkResponse(link *Bitlink) map[string]any {
+	response := map[string]any{
+		"id":       link.Id,
+		"link":     "https://" + link.Id,
+		"long_url": link.LongUrl,
+		"title":    link.Title,
+		"tags":     link.Tags,
+		"archived": link.Archived,
+	}
+	if link.ExpirationAt != "" {
+		response["expiration_at"] = link.ExpirationAt
+	}
+	return response
+}
+
+func clone(link *Bitlink) Bitlink {
+	c := *link
+	c.Tags = slices.Clone(link.Tags)
+	c.Clicks = maps.Clone(link.Clicks)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string, description string) {
+	writeJSON(w, statusCode, map[string]string{"message": message, "description": description})
+}
@@ -0,0 +1,234 @@
+package bilty
+
+import (
+	"challenge/pkg/api/bilty/bitlytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEmulated creates client of the emulator, delays between retries are skipped
+func newEmulated(t *testing.T, opts ...Option) (*Bilty, *bitlytest.Server) {
+	t.Helper()
+	srv := bitlytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBaseUrl(srv.URL)}, opts...)
+	b := NewBilty(bitlytest.DefaultToken, srv.Client(), opts...)
+	b.sleep = func(time.Duration) {}
+	return b, srv
+}
+
+func TestEmulator_Lifecycle(t *testing.T) {
+	b, srv := newEmulated(t)
+
+	link, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	again, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.Equal(t, link, again)
+
+	long, err := b.ExpandShortLink(link)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/", long)
+
+	title := "Search"
+	require.NoError(t, b.UpdateLink(link, &title, []string{"a", "b"}))
+	require.NoError(t, b.ArchiveLink(link))
+	state, ok := srv.Bitlink(bitlinkId(link))
+	require.True(t, ok)
+	assert.Equal(t, "Search", state.Title)
+	assert.Equal(t, []string{"a", "b"}, state.Tags)
+	assert.True(t, state.Archived)
+
+	until := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, srv.Click(bitlinkId(link), until, 3))
+	require.NoError(t, srv.Click(bitlinkId(link), until.AddDate(0, 0, -1), 2))
+	require.NoError(t, srv.Click(bitlinkId(link), until.AddDate(0, 0, -10), 4))
+	total, daily, err := b.GetLinkStats(link, 7, until)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, 3, daily["2024-03-10"])
+	assert.Equal(t, 2, daily["2024-03-09"])
+	assert.Len(t, daily, 7)
+
+	total, _, err = b.GetLinkStats(link, 0, until)
+	require.NoError(t, err)
+	assert.Equal(t, 9, total)
+
+	require.NoError(t, b.DeleteLink(link))
+	_, err = b.ExpandShortLink(link)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEmulator_Failures(t *testing.T) {
+	tc := []struct {
+		name        string
+		failure     bitlytest.Failure
+		opts        []Option
+		wantErr     error
+		wantStatus  int
+		wantMessage string
+		wantCalls   int
+	}{
+		{
+			name: "rate limited, then retried",
+			failure: bitlytest.Failure{
+				Path: shortenUrl, Times: 2, StatusCode: http.StatusTooManyRequests, Message: "RATE_LIMIT_EXCEEDED",
+				Header: http.Header{"Retry-After": []string{"1"}},
+			},
+			opts:      []Option{WithRetry(3, time.Millisecond, time.Second)},
+			wantCalls: 3,
+		},
+		{
+			name:        "rate limited, retries exhausted",
+			failure:     bitlytest.Failure{Path: shortenUrl, StatusCode: http.StatusTooManyRequests, Message: "RATE_LIMIT_EXCEEDED"},
+			opts:        []Option{WithRetry(2, time.Millisecond, time.Second)},
+			wantErr:     ErrApiError,
+			wantStatus:  http.StatusTooManyRequests,
+			wantMessage: "RATE_LIMIT_EXCEEDED",
+			wantCalls:   3,
+		},
+		{
+			name:        "quota exceeded",
+			failure:     bitlytest.Failure{Path: shortenUrl, StatusCode: http.StatusForbidden, Message: "MONTHLY_ENCODE_LIMIT_EXCEEDED"},
+			opts:        []Option{WithRetry(3, time.Millisecond, time.Second)},
+			wantErr:     ErrApiError,
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "MONTHLY_ENCODE_LIMIT_EXCEEDED",
+			wantCalls:   1,
+		},
+		{
+			name:      "gateway error, then retried",
+			failure:   bitlytest.Failure{Path: shortenUrl, Times: 1, StatusCode: http.StatusBadGateway, Body: "<html>Bad Gateway</html>"},
+			opts:      []Option{WithRetry(3, time.Millisecond, time.Second)},
+			wantCalls: 2,
+		},
+		{
+			name:        "gateway error without retries",
+			failure:     bitlytest.Failure{Path: shortenUrl, StatusCode: http.StatusServiceUnavailable, Body: "<html>Unavailable</html>"},
+			wantErr:     ErrApiError,
+			wantStatus:  http.StatusServiceUnavailable,
+			wantMessage: http.StatusText(http.StatusServiceUnavailable),
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b, srv := newEmulated(t, tt.opts...)
+			srv.Fail(tt.failure)
+
+			_, err := b.CreateShortLink("https://www.google.com/")
+			assert.Len(t, srv.Requests(), tt.wantCalls)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			var apiErr *ApiError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+			assert.Equal(t, tt.wantMessage, apiErr.Message)
+		})
+	}
+}
+
+func TestEmulator_EditLink(t *testing.T) {
+	b, srv := newEmulated(t)
+	link, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	// Long url and metadata are changed with single request, so they can't be applied partially
+	title := "Code"
+	calls := len(srv.Requests())
+	require.NoError(t, b.EditLink(link, "https://github.com/", &title, []string{"a"}))
+	assert.Equal(t, []string{"PATCH /v4/bitlinks/" + bitlinkId(link)}, srv.Requests()[calls:])
+	state, ok := srv.Bitlink(bitlinkId(link))
+	require.True(t, ok)
+	assert.Equal(t, "https://github.com/", state.LongUrl)
+	assert.Equal(t, "Code", state.Title)
+	assert.Equal(t, []string{"a"}, state.Tags)
+
+	srv.Fail(bitlytest.Failure{Method: http.MethodPatch, StatusCode: http.StatusPaymentRequired, Message: "UPGRADE_REQUIRED"})
+	assert.ErrorIs(t, b.EditLink(link, "https://www.google.com/", nil, nil), ErrUpgradeRequired)
+}
+
+func TestEmulator_Expiring(t *testing.T) {
+	b, srv := newEmulated(t, WithExpiration(true))
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	permanent, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	// Bitly returns the same bitlink, it has been handed out as permanent, so it must stay so
+	_, err = b.CreateExpiringShortLink("https://www.google.com/", "", "", "", expiresAt)
+	assert.ErrorIs(t, err, ErrLinkExists)
+	state, ok := srv.Bitlink(bitlinkId(permanent))
+	require.True(t, ok)
+	assert.Empty(t, state.ExpirationAt)
+
+	expiring, err := b.CreateExpiringShortLink("https://github.com/", "", "", "", expiresAt)
+	require.NoError(t, err)
+	assert.NotEqual(t, permanent, expiring)
+	state, ok = srv.Bitlink(bitlinkId(expiring))
+	require.True(t, ok)
+	assert.Equal(t, "2030-01-01T00:00:00Z", state.ExpirationAt)
+
+	// Bitlink which can't expire is deleted, so it isn't handed out as permanent later
+	srv.Fail(bitlytest.Failure{Method: http.MethodPatch, Times: 1, StatusCode: http.StatusPaymentRequired, Message: "UPGRADE_REQUIRED"})
+	calls := len(srv.Requests())
+	_, err = b.CreateExpiringShortLink("https://example.com/", "", "", "", expiresAt)
+	assert.ErrorIs(t, err, ErrUpgradeRequired)
+	requests := srv.Requests()[calls:]
+	require.Len(t, requests, 3)
+	assert.Equal(t, strings.Replace(requests[1], http.MethodPatch, http.MethodDelete, 1), requests[2])
+	_, ok = srv.Bitlink(strings.TrimPrefix(requests[2], "DELETE /v4/bitlinks/"))
+	assert.False(t, ok)
+}
+
+func TestEmulator_Unauthorized(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+
+	_, err := NewBilty("wrong", srv.Client(), WithBaseUrl(srv.URL)).CreateShortLink("https://www.google.com/")
+	var apiErr *ApiError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+}
+
+func TestEmulator_Headers(t *testing.T) {
+	var got http.Header
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/bitly")
+		httputil.NewSingleHostReverseProxy(mustParse(t, srv.URL)).ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	b := NewBilty(bitlytest.DefaultToken, proxy.Client(),
+		WithBaseUrl(proxy.URL+"/bitly/"),
+		WithUserAgent("challenge/1.0"),
+		WithHeaders(map[string]string{"x-proxy-key": "secret", "Authorization": "Bearer other"}),
+	)
+	_, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.Equal(t, "challenge/1.0", got.Get("User-Agent"))
+	assert.Equal(t, "secret", got.Get("X-Proxy-Key"))
+	assert.Equal(t, "Bearer "+bitlytest.DefaultToken, got.Get("Authorization"))
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
@@ -0,0 +1,69 @@
+package bilty
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is token bucket which refills with rate tokens per second up to burst
+//
+// Every request takes a token, when bucket is empty caller waits until its token is refilled,
+// so waiting requests are served in order of their arrival.
+// Not positive rate means no limit, limiter still respects pauses requested by API
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// pausedUntil is set when API reports exhausted rate limit
+	pausedUntil time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// reserve takes a token and returns how long caller must wait before sending request
+func (l *limiter) reserve(now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	if l.rate > 0 {
+		if !l.last.IsZero() {
+			l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		}
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+
+	return max(wait, l.pausedUntil.Sub(now))
+}
+
+// pause holds all requests until given moment
+func (l *limiter) pause(until time.Time) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
@@ -0,0 +1,78 @@
+package bilty
+
+import (
+	"strings"
+	"time"
+)
+
+// Option configures Bilty client on creation
+type Option func(*Bilty)
+
+// WithBaseUrl sends requests to API at given url instead of Bitly API, e.g. to proxy or emulator
+func WithBaseUrl(baseUrl string) Option {
+	return func(b *Bilty) {
+		b.baseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
+// WithUserAgent sets User-Agent header of API requests
+func WithUserAgent(userAgent string) Option {
+	return func(b *Bilty) {
+		b.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds given headers to every API request, they can't override authorization of the client
+func WithHeaders(headers map[string]string) Option {
+	return func(b *Bilty) {
+		b.headers = headers
+	}
+}
+
+// WithDomain sets default domain of created bitlinks, e.g. branded short domain
+func WithDomain(domain string) Option {
+	return func(b *Bilty) {
+		b.domain = domain
+	}
+}
+
+// WithGroupGuid sets default group of created bitlinks
+func WithGroupGuid(guid string) Option {
+	return func(b *Bilty) {
+		b.groupGuid = guid
+	}
+}
+
+// WithExpiration enables expiration of created bitlinks, it's available only for some Bitly plans
+func WithExpiration(enabled bool) Option {
+	return func(b *Bilty) {
+		b.expiration = enabled
+	}
+}
+
+// WithRateLimit limits requests on client side to given amount per period, up to burst requests
+// can be sent at once. Requests over the limit wait for their turn instead of failing
+func WithRateLimit(requests int, per time.Duration, burst int) Option {
+	return func(b *Bilty) {
+		if requests <= 0 || per <= 0 {
+			return
+		}
+		b.limiter = newLimiter(float64(requests)/per.Seconds(), burst)
+	}
+}
+
+// WithRetry enables retries of failed requests with exponential backoff between baseDelay and maxDelay
+//
+// Rate limited requests are always retried, network errors and 502, 503, 504 statuses are retried
+// only for idempotent requests. Retry-After and rate limit reset headers of API override backoff
+func WithRetry(maxRetries int, baseDelay time.Duration, maxDelay time.Duration) Option {
+	return func(b *Bilty) {
+		b.maxRetries = maxRetries
+		if baseDelay > 0 {
+			b.retryBaseDelay = baseDelay
+		}
+		if maxDelay > 0 {
+			b.retryMaxDelay = maxDelay
+		}
+	}
+}
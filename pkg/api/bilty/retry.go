@@ -0,0 +1,155 @@
+package bilty
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryAfterHeader         = "Retry-After"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// idempotentPosts are POST endpoints which return the same result when repeated,
+// shorten returns existing bitlink of the same long url(with 200 instead of 201, see shorten) and expand only reads
+var idempotentPosts = map[string]bool{
+	shortenUrl: true,
+	expandUrl:  true,
+}
+
+// idempotent reports whether request can be safely repeated after it possibly reached API
+func idempotent(method string, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return idempotentPosts[path]
+	default:
+		return false
+	}
+}
+
+// retryDelay decides whether failed attempt should be retried and how long to wait before it
+//
+// 429 means request was rejected before processing, so it's retried for any request.
+// Network errors and gateway statuses are retried only for repeatable requests, see idempotent.
+// Attempt is not retried when API asks to wait longer than max delay
+func (b *Bilty) retryDelay(repeatable bool, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= b.maxRetries {
+		return 0, false
+	}
+
+	if err != nil {
+		if !repeatable {
+			return 0, false
+		}
+		return b.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !repeatable {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	delay, ok := serverDelay(resp.Header, time.Now())
+	if !ok {
+		return b.backoff(attempt), true
+	}
+	if delay > b.retryMaxDelay {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// backoff returns exponentially growing delay of given attempt capped by max delay,
+// delay is randomized in its upper half, so concurrent clients don't retry simultaneously
+func (b *Bilty) backoff(attempt int) time.Duration {
+	delay := b.retryMaxDelay
+	if attempt < 32 {
+		delay = min(b.retryBaseDelay<<attempt, b.retryMaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// serverDelay returns delay requested by API with Retry-After(seconds or http date) or rate limit reset headers
+func serverDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get(retryAfterHeader); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	if reset, ok := rateLimitReset(header, now); ok {
+		return max(reset.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// rateLimitReset returns moment when exhausted rate limit is reset
+//
+// Reset header may hold either unix time or seconds until reset
+func rateLimitReset(header http.Header, now time.Time) (time.Time, bool) {
+	value, err := strconv.ParseInt(header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Anything before 2001 can't be unix time of the future reset
+	if value > 1e9 {
+		return time.Unix(value, 0), true
+	}
+	return now.Add(time.Duration(value) * time.Second), true
+}
+
+// observeRateLimit pauses limiter when API reports that no requests are left until reset
+func (b *Bilty) observeRateLimit(header http.Header) {
+	if header.Get(rateLimitRemainingHeader) != "0" {
+		return
+	}
+
+	if reset, ok := rateLimitReset(header, time.Now()); ok {
+		b.limiter.pause(reset)
+	}
+}
+
+// wait blocks for given duration or until ctx is done, tests replace sleep to avoid real delays
+func (b *Bilty) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	if b.sleep != nil {
+		b.sleep(d)
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
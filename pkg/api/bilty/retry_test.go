@@ -0,0 +1,310 @@
+package bilty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// scriptedResponse is response of single attempt, network error is returned if err is set
+type scriptedResponse struct {
+	statusCode int
+	header     http.Header
+	err        error
+}
+
+// newScriptedMock returns client which responds with given responses in order, the last one is repeated
+func newScriptedMock(t *testing.T, responses []scriptedResponse, opts ...Option) (*Bilty, *int, *[]time.Duration) {
+	attempts := 0
+	var sleeps []time.Duration
+
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			resp := responses[min(attempts, len(responses)-1)]
+			attempts++
+			if r.Body != nil {
+				// Body must be sent again on every attempt
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.NotEmpty(t, body)
+			}
+			if resp.err != nil {
+				return nil, resp.err
+			}
+
+			respBody, err := json.Marshal(Bitlink{Id: "bit.ly/abc", Link: "https://bit.ly/abc"})
+			require.NoError(t, err)
+			header := resp.header
+			if header == nil {
+				header = http.Header{}
+			}
+			return &http.Response{
+				StatusCode: resp.statusCode,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewReader(respBody)),
+			}, nil
+		}),
+	}
+
+	b := NewBilty("token", client, opts...)
+	b.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+	}
+
+	return b, &attempts, &sleeps
+}
+
+// headers creates header from key value pairs, keys are canonicalized as in real responses
+func headers(kv ...string) http.Header {
+	header := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		header.Set(kv[i], kv[i+1])
+	}
+	return header
+}
+
+func TestRetry_TestCases(t *testing.T) {
+	retryAfter := func(value string) http.Header {
+		return headers(retryAfterHeader, value)
+	}
+
+	tc := []struct {
+		name         string
+		call         func(b *Bilty) error
+		responses    []scriptedResponse
+		wantAttempts int
+		wantSleeps   []time.Duration
+		wantStatus   int
+	}{
+		{
+			name: "rate limited non idempotent request",
+			call: func(b *Bilty) error {
+				return b.do(context.Background(), http.MethodPost, customBitlinkUrl, CustomBitlinkRequest{CustomBitlink: "bit.ly/a"}, nil)
+			},
+			responses:    []scriptedResponse{{statusCode: http.StatusTooManyRequests, header: retryAfter("2")}, {statusCode: http.StatusOK}},
+			wantAttempts: 2,
+			wantSleeps:   []time.Duration{2 * time.Second},
+		},
+		{
+			name:         "unavailable idempotent request",
+			call:         func(b *Bilty) error { return b.UpdateLink("bit.ly/abc", nil, []string{"a"}) },
+			responses:    []scriptedResponse{{statusCode: http.StatusServiceUnavailable}, {statusCode: http.StatusOK}},
+			wantAttempts: 2,
+		},
+		{
+			name: "unavailable non idempotent request",
+			call: func(b *Bilty) error {
+				return b.do(context.Background(), http.MethodPost, customBitlinkUrl, CustomBitlinkRequest{CustomBitlink: "bit.ly/a"}, nil)
+			},
+			responses:    []scriptedResponse{{statusCode: http.StatusServiceUnavailable}, {statusCode: http.StatusOK}},
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:         "network error of shorten",
+			call:         func(b *Bilty) error { _, err := b.CreateShortLink("https://www.google.com/"); return err },
+			responses:    []scriptedResponse{{err: errors.New("connection reset")}, {statusCode: http.StatusOK}},
+			wantAttempts: 2,
+		},
+		{
+			name: "unavailable shorten of expiring link",
+			call: func(b *Bilty) error {
+				// Lost attempt could create bitlink, repeated one would get it with 200 as existing
+				b.expiration = true
+				_, err := b.CreateExpiringShortLink("https://www.google.com/", "", "", "", time.Now().Add(time.Hour))
+				return err
+			},
+			responses:    []scriptedResponse{{statusCode: http.StatusServiceUnavailable}, {statusCode: http.StatusOK}},
+			wantAttempts: 1,
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name: "bad gateway of shorten with alias",
+			call: func(b *Bilty) error {
+				_, err := b.CreateCustomShortLink("https://www.google.com/", "campaign", "", "")
+				return err
+			},
+			responses:    []scriptedResponse{{statusCode: http.StatusBadGateway}, {statusCode: http.StatusOK}},
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name: "rate limited shorten of expiring link",
+			call: func(b *Bilty) error {
+				b.expiration = true
+				_, err := b.CreateExpiringShortLink("https://www.google.com/", "", "", "", time.Now().Add(time.Hour))
+				return err
+			},
+			responses:    []scriptedResponse{{statusCode: http.StatusTooManyRequests}, {statusCode: http.StatusCreated}},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries exhausted",
+			call:         func(b *Bilty) error { return b.UpdateLink("bit.ly/abc", nil, []string{"a"}) },
+			responses:    []scriptedResponse{{statusCode: http.StatusTooManyRequests}},
+			wantAttempts: 4,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+		{
+			name:         "retry after exceeds max delay",
+			call:         func(b *Bilty) error { return b.UpdateLink("bit.ly/abc", nil, []string{"a"}) },
+			responses:    []scriptedResponse{{statusCode: http.StatusTooManyRequests, header: retryAfter("3600")}},
+			wantAttempts: 1,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+		{
+			name:         "client error",
+			call:         func(b *Bilty) error { return b.UpdateLink("bit.ly/abc", nil, []string{"a"}) },
+			responses:    []scriptedResponse{{statusCode: http.StatusBadRequest}},
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b, attempts, sleeps := newScriptedMock(t, tt.responses, WithRetry(3, time.Millisecond, time.Minute))
+
+			err := tt.call(b)
+			assert.Equal(t, tt.wantAttempts, *attempts)
+			if tt.wantSleeps != nil {
+				assert.Equal(t, tt.wantSleeps, *sleeps)
+			}
+			if tt.wantStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var apiErr *ApiError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := NewBilty("token", nil, WithRetry(10, 100*time.Millisecond, time.Second))
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		got := b.backoff(attempt)
+		assert.GreaterOrEqual(t, got, want/2)
+		assert.LessOrEqual(t, got, want)
+	}
+}
+
+func TestServerDelay_TestCases(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tc := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOk bool
+	}{
+		{
+			name:   "retry after seconds",
+			header: headers(retryAfterHeader, "5"),
+			want:   5 * time.Second,
+			wantOk: true,
+		},
+		{
+			name:   "retry after date",
+			header: headers(retryAfterHeader, now.Add(time.Minute).Format(http.TimeFormat)),
+			want:   time.Minute,
+			wantOk: true,
+		},
+		{
+			name:   "reset unix time",
+			header: headers(rateLimitResetHeader, strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)),
+			want:   30 * time.Second,
+			wantOk: true,
+		},
+		{
+			name:   "reset seconds",
+			header: headers(rateLimitResetHeader, "10"),
+			want:   10 * time.Second,
+			wantOk: true,
+		},
+		{
+			name:   "no headers",
+			header: http.Header{},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := serverDelay(tt.header, now)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(2, 2)
+
+	// Burst is available at once, next requests wait for refill in order of arrival
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(now))
+	assert.Equal(t, time.Second, l.reserve(now))
+
+	// Refill doesn't exceed burst
+	later := now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), l.reserve(later))
+	assert.Equal(t, time.Duration(0), l.reserve(later))
+	assert.Equal(t, 500*time.Millisecond, l.reserve(later))
+
+	l.pause(later.Add(time.Minute))
+	assert.Equal(t, time.Minute, l.reserve(later))
+}
+
+func TestObserveRateLimit(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	b, _, sleeps := newScriptedMock(t, []scriptedResponse{{
+		statusCode: http.StatusOK,
+		header:     headers(rateLimitRemainingHeader, "0", rateLimitResetHeader, reset),
+	}})
+
+	_, err := b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.Empty(t, *sleeps)
+
+	// Exhausted limit holds next request until reset
+	_, err = b.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	require.Len(t, *sleeps, 1)
+	assert.Greater(t, (*sleeps)[0], 59*time.Minute)
+}
+
+func TestContextCancellation(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			// Transport fails the same way as real one when request context is done
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}),
+	}
+	b := NewBilty("token", client, WithRetry(3, time.Millisecond, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := b.CreateShortLinkContext(ctx, "https://www.google.com/")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Waiting for rate limiter stops when context is done
+	limited := NewBilty("token", client, WithRateLimit(1, time.Hour, 1))
+	limited.limiter.reserve(time.Now())
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = limited.ExpandShortLinkContext(canceled, "bit.ly/abc")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,14 +1,109 @@
 package bilty
 
+import "fmt"
+
 type CreateLinkRequest struct {
-	Link string `json:"long_url"`
+	Link      string `json:"long_url"`
+	Domain    string `json:"domain,omitempty"`
+	GroupGuid string `json:"group_guid,omitempty"`
 }
 
 type CreateLinkResponse struct {
+	Id        string `json:"id"`
 	ShortLink string `json:"link"`
 }
 
+type CustomBitlinkRequest struct {
+	CustomBitlink string `json:"custom_bitlink"`
+	BitlinkId     string `json:"bitlink_id"`
+}
+
+type CustomBitlinkResponse struct {
+	CustomBitlink string `json:"custom_bitlink"`
+}
+
+type ExpandLinkRequest struct {
+	BitlinkId string `json:"bitlink_id"`
+}
+
+type ExpandLinkResponse struct {
+	Id      string `json:"id"`
+	LongUrl string `json:"long_url"`
+}
+
+type ClicksSummaryResponse struct {
+	TotalClicks int    `json:"total_clicks"`
+	Unit        string `json:"unit"`
+	Units       int    `json:"units"`
+}
+
+type ClicksResponse struct {
+	LinkClicks []LinkClicks `json:"link_clicks"`
+	Unit       string       `json:"unit"`
+	Units      int          `json:"units"`
+}
+
+type LinkClicks struct {
+	Clicks int    `json:"clicks"`
+	Date   string `json:"date"`
+}
+
+type Group struct {
+	Guid             string `json:"guid"`
+	Name             string `json:"name"`
+	OrganizationGuid string `json:"organization_guid"`
+	IsActive         bool   `json:"is_active"`
+}
+
+type GroupsResponse struct {
+	Groups []Group `json:"groups"`
+}
+
+type BrandedDomainsResponse struct {
+	Domains []string `json:"bsds"`
+}
+
+// UpdateBitlinkRequest changes only fields which are set, empty non-nil Tags remove all tags
+type UpdateBitlinkRequest struct {
+	LongUrl  *string   `json:"long_url,omitempty"`
+	Title    *string   `json:"title,omitempty"`
+	Tags     *[]string `json:"tags,omitempty"`
+	Archived *bool     `json:"archived,omitempty"`
+	// ExpirationAt is time in RFC 3339 format, when bitlink stops redirecting
+	ExpirationAt *string `json:"expiration_at,omitempty"`
+}
+
+type Bitlink struct {
+	Id           string   `json:"id"`
+	Link         string   `json:"link"`
+	LongUrl      string   `json:"long_url"`
+	Title        string   `json:"title"`
+	Tags         []string `json:"tags"`
+	Archived     bool     `json:"archived"`
+	ExpirationAt string   `json:"expiration_at,omitempty"`
+}
+
 type ErrorMessage struct {
 	Message     string `json:"message"`
 	Description string `json:"description"`
 }
+
+// ApiError is returned when Bitly API responds with unsuccessful status
+//
+// errors.Is(err, ErrApiError) reports true for it
+type ApiError struct {
+	StatusCode  int
+	Message     string
+	Description string
+}
+
+func (e *ApiError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("%v: %d %s", ErrApiError, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%v: %d %s: %s", ErrApiError, e.StatusCode, e.Message, e.Description)
+}
+
+func (e *ApiError) Is(target error) bool {
+	return target == ErrApiError
+}
@@ -0,0 +1,29 @@
+package timercheck
+
+import "strings"
+
+// Option configures TimerCheck client on creation
+type Option func(*TimerCheck)
+
+// WithBaseUrl sends requests to API at given url instead of timercheck.io, e.g. to proxy or mirror
+func WithBaseUrl(baseUrl string) Option {
+	return func(t *TimerCheck) {
+		if baseUrl != "" {
+			t.baseUrl = strings.TrimSuffix(baseUrl, "/") + "/"
+		}
+	}
+}
+
+// WithUserAgent sets User-Agent header of API requests
+func WithUserAgent(userAgent string) Option {
+	return func(t *TimerCheck) {
+		t.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds given headers to every API request
+func WithHeaders(headers map[string]string) Option {
+	return func(t *TimerCheck) {
+		t.headers = headers
+	}
+}
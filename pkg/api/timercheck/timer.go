@@ -3,6 +3,9 @@
 package timercheck
 
 import (
+	"challenge/pkg/timer"
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,24 +13,36 @@ import (
 	"net/http"
 )
 
+// ErrTimedOut and ErrNotExists are errors of timer.TimerBackend, so TimerCheck is used as backend of timer.Timer
 var (
 	ErrInternal  = errors.New("internal library error")
-	ErrTimedOut  = errors.New("timer timed out")
-	ErrNotExists = errors.New("timer not exists")
+	ErrTimedOut  = timer.ErrTimedOut
+	ErrNotExists = timer.ErrNotExists
 )
 
 const (
-	host = "https://timercheck.io/"
+	defaultBaseUrl = "https://timercheck.io/"
 )
 
 type TimerCheck struct {
 	client *http.Client
+
+	// baseUrl of API with trailing slash, timercheck.io is used if empty
+	// userAgent and headers are sent with every request, see options
+	baseUrl   string
+	userAgent string
+	headers   map[string]string
 }
 
-func NewTimerCheck(c *http.Client) *TimerCheck {
-	return &TimerCheck{
+func NewTimerCheck(c *http.Client, opts ...Option) *TimerCheck {
+	t := &TimerCheck{
 		client: c,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
 }
 
 // CreateTimer creates new timer using timercheck.io API
@@ -35,16 +50,22 @@ func NewTimerCheck(c *http.Client) *TimerCheck {
 //
 // ErrInternal returned when something goes wrong with API or inside this function
 func (t *TimerCheck) CreateTimer(name string, seconds int) error {
+	return t.CreateTimerContext(context.Background(), name, seconds)
+}
+
+// CreateTimerContext is CreateTimer which stops waiting for API when ctx is done
+func (t *TimerCheck) CreateTimerContext(ctx context.Context, name string, seconds int) error {
 
-	req, err := http.NewRequest("GET", host+name+"/"+fmt.Sprintf("%d", seconds), nil)
+	req, err := t.newRequest(ctx, http.MethodGet, name+"/"+fmt.Sprintf("%d", seconds))
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return fmt.Errorf("%w: %w", ErrInternal, err)
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("%w: %v", ErrInternal, "got bad http status code")
@@ -60,8 +81,13 @@ func (t *TimerCheck) CreateTimer(name string, seconds int) error {
 //
 // ErrNotExists returned when timer with given name have never been exist
 func (t *TimerCheck) CheckTimer(name string) (remain int, elapsed int, err error) {
+	return t.CheckTimerContext(context.Background(), name)
+}
+
+// CheckTimerContext is CheckTimer which stops waiting for API when ctx is done
+func (t *TimerCheck) CheckTimerContext(ctx context.Context, name string) (remain int, elapsed int, err error) {
 
-	req, err := http.NewRequest("GET", host+name, nil)
+	req, err := t.newRequest(ctx, http.MethodGet, name)
 	if err != nil {
 		err = fmt.Errorf("%w: %v", ErrInternal, err)
 		return
@@ -69,9 +95,10 @@ func (t *TimerCheck) CheckTimer(name string) (remain int, elapsed int, err error
 
 	resp, err := t.client.Do(req)
 	if err != nil {
-		err = fmt.Errorf("%w: %v", ErrInternal, err)
+		err = fmt.Errorf("%w: %w", ErrInternal, err)
 		return
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == 504 {
 		err = fmt.Errorf("%w: %v", ErrTimedOut, "timer timed out")
@@ -105,3 +132,70 @@ func (t *TimerCheck) CheckTimer(name string) (remain int, elapsed int, err error
 	elapsed = int(timerResp.Elapsed)
 	return
 }
+
+// DeleteTimer deletes timer with given name
+//
+// ErrNotExists returned when timer with given name doesn't exist
+// ErrInternal returned when something goes wrong with API or inside this function
+func (t *TimerCheck) DeleteTimer(name string) error {
+	return t.DeleteTimerContext(context.Background(), name)
+}
+
+// DeleteTimerContext is DeleteTimer which stops waiting for API when ctx is done
+func (t *TimerCheck) DeleteTimerContext(ctx context.Context, name string) error {
+	req, err := t.newRequest(ctx, http.MethodDelete, name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return fmt.Errorf("%w: %v", ErrNotExists, "timer never been created")
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("%w: %v", ErrInternal, "got bad http status code")
+	}
+
+	return nil
+}
+
+// ExtendTimer adds seconds to remaining time of running timer
+//
+// API can't change running timer, so timer is created again with extended remaining seconds
+// and its elapsed seconds start from zero
+//
+// ErrTimedOut and ErrNotExists returned the same way as by CheckTimer
+func (t *TimerCheck) ExtendTimer(name string, seconds int) error {
+	return t.ExtendTimerContext(context.Background(), name, seconds)
+}
+
+// ExtendTimerContext is ExtendTimer which stops waiting for API when ctx is done
+func (t *TimerCheck) ExtendTimerContext(ctx context.Context, name string, seconds int) error {
+	remain, _, err := t.CheckTimerContext(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return t.CreateTimerContext(ctx, name, remain+seconds)
+}
+
+// newRequest creates request to given path of API with configured headers
+func (t *TimerCheck) newRequest(ctx context.Context, method string, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, cmp.Or(t.baseUrl, defaultBaseUrl)+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	return req, nil
+}
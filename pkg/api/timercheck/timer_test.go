@@ -2,6 +2,7 @@ package timercheck
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/brianvoe/gofakeit/v7"
@@ -9,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -164,6 +167,24 @@ func TestCreateTimer_TestCases(t *testing.T) {
 	}
 }
 
+func TestCheckTimerContext_Canceled(t *testing.T) {
+	timer := &TimerCheck{
+		client: &http.Client{
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				<-r.Context().Done()
+				return nil, r.Context().Err()
+			}),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := timer.CheckTimerContext(ctx, "test")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, timer.CreateTimerContext(ctx, "test", 10), context.Canceled)
+}
+
 // API test
 func TestTimerCheck_TestCases(t *testing.T) {
 	type args struct {
@@ -253,3 +274,128 @@ func TestTimerCheck_NotExistingTimer(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not exists")
 }
+
+func TestOptions(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_ = json.NewEncoder(w).Encode(TimerResponse{Remaining: 10, Elapsed: 2})
+	}))
+	defer srv.Close()
+
+	timer := NewTimerCheck(srv.Client(),
+		WithBaseUrl(srv.URL+"/mirror"),
+		WithUserAgent("challenge/1.0"),
+		WithHeaders(map[string]string{"x-api-key": "secret"}),
+	)
+	remain, _, err := timer.CheckTimer("test")
+	require.NoError(t, err)
+	assert.Equal(t, 10, remain)
+	assert.Equal(t, "/mirror/test", got.URL.Path)
+	assert.Equal(t, "challenge/1.0", got.Header.Get("User-Agent"))
+	assert.Equal(t, "secret", got.Header.Get("X-Api-Key"))
+
+	require.NoError(t, timer.CreateTimer("test", 60))
+	assert.Equal(t, "/mirror/test/60", got.URL.Path)
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTimerCheck_ClosesBody(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusNotFound, http.StatusGatewayTimeout, http.StatusBadRequest} {
+		t.Run(fmt.Sprint(statusCode), func(t *testing.T) {
+			var bodies []*closeTracker
+			timer := NewTimerCheck(&http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					body := &closeTracker{Reader: strings.NewReader(`{"seconds_remaining":10}`)}
+					bodies = append(bodies, body)
+					return &http.Response{StatusCode: statusCode, Body: body}, nil
+				}),
+			})
+
+			_ = timer.CreateTimer("test", 60)
+			_, _, _ = timer.CheckTimer("test")
+			require.Len(t, bodies, 2)
+			for _, body := range bodies {
+				assert.True(t, body.closed)
+			}
+		})
+	}
+}
+
+func TestDeleteTimer_TestCases(t *testing.T) {
+	tc := []struct {
+		name       string
+		statusCode int
+		wantErr    error
+	}{
+		{name: "ok", statusCode: http.StatusOK},
+		{name: "not exists", statusCode: http.StatusNotFound, wantErr: ErrNotExists},
+		{name: "bad status", statusCode: http.StatusBadGateway, wantErr: ErrInternal},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := NewTimerCheck(&http.Client{
+				Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					assert.Equal(t, http.MethodDelete, r.Method)
+					assert.Equal(t, "/test", r.URL.Path)
+					return &http.Response{StatusCode: tt.statusCode, Body: io.NopCloser(strings.NewReader(""))}, nil
+				}),
+			})
+
+			err := timer.DeleteTimer("test")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestExtendTimer_TestCases(t *testing.T) {
+	tc := []struct {
+		name        string
+		checkStatus int
+		wantPath    string
+		wantErr     error
+	}{
+		{name: "running", checkStatus: http.StatusOK, wantPath: "/test/15"},
+		{name: "timed out", checkStatus: http.StatusGatewayTimeout, wantErr: ErrTimedOut},
+		{name: "not exists", checkStatus: http.StatusNotFound, wantErr: ErrNotExists},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var created string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/test" {
+					w.WriteHeader(tt.checkStatus)
+					_ = json.NewEncoder(w).Encode(TimerResponse{Remaining: 10.5, Elapsed: 4})
+					return
+				}
+				created = r.URL.Path
+			}))
+			defer srv.Close()
+
+			// Running timer is created again with remaining seconds plus extension
+			err := NewTimerCheck(srv.Client(), WithBaseUrl(srv.URL)).ExtendTimer("test", 5)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, created)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, created)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func init() {
+	shortenerCommand.AddCommand(archiveCommand)
+	archiveCommand.Flags().StringVarP(&archiveUrl, "url", "u", "", "short link that'll be archived")
+}
+
+var archiveUrl string
+var archiveCommand = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive short link",
+	Long:  `gRPC call that'll archive given short link, archived self-hosted links stop redirecting'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if archiveUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		archived, err := client.ArchiveLink(context.Background(), &proto.Link{Data: archiveUrl})
+		if err != nil {
+			fmt.Printf("cannot archive link: %v\n", err)
+			return
+		}
+
+		fmt.Printf("archived link: %s\n", archived.GetData())
+	},
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func init() {
+	shortenerCommand.AddCommand(deleteCommand)
+	deleteCommand.Flags().StringVarP(&deleteUrl, "url", "u", "", "short link that'll be deleted")
+}
+
+var deleteUrl string
+var deleteCommand = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete short link",
+	Long:  `gRPC call that'll delete given short link'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if deleteUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		if _, err := client.DeleteLink(context.Background(), &proto.Link{Data: deleteUrl}); err != nil {
+			fmt.Printf("cannot delete link: %v\n", err)
+			return
+		}
+
+		fmt.Printf("deleted link: %s\n", deleteUrl)
+	},
+}
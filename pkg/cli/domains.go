@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func init() {
+	shortenerCommand.AddCommand(domainsCommand)
+}
+
+var domainsCommand = &cobra.Command{
+	Use:   "domains",
+	Short: "List domains and groups",
+	Long:  `gRPC call that'll list domains and groups which can be used with --domain and --group flags'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		domains, err := client.ListLinkDomains(context.Background(), &proto.Placeholder{})
+		if err != nil {
+			fmt.Printf("cannot list domains: %v\n", err)
+			return
+		}
+
+		fmt.Println("domains:")
+		for _, d := range domains.GetDomains() {
+			fmt.Printf("  %s\n", d)
+		}
+		fmt.Println("groups:")
+		for _, g := range domains.GetGroups() {
+			fmt.Printf("  %s\t%s\n", g.GetGuid(), g.GetName())
+		}
+	},
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func init() {
+	rootCmd.AddCommand(expandCommand)
+	expandCommand.Flags().StringVarP(&shortUrl, "url", "u", "", "short link that'll be expanded")
+}
+
+var shortUrl string
+var expandCommand = &cobra.Command{
+	Use:   "expand",
+	Short: "Expand short link",
+	Long:  `gRPC call that'll resolve given short link back to its long url'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if shortUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		expanded, err := client.ExpandShortLink(context.Background(), &proto.Link{Data: shortUrl})
+		if err != nil {
+			fmt.Printf("cannot expand link: %v\n", err)
+			return
+		}
+
+		fmt.Printf("long link: %s\n", expanded.GetData())
+	},
+}
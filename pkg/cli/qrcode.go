@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	shortenerCommand.AddCommand(qrCodeCommand)
+	qrCodeCommand.Flags().StringVarP(&qrCodeUrl, "url", "u", "", "short link to render")
+	qrCodeCommand.Flags().StringVarP(&qrCodeOut, "out", "o", "", "file the image is written to")
+	qrCodeCommand.Flags().StringVar(&qrCodeFormat, "format", "", "image format, png or svg (default: by extension of --out, png otherwise)")
+	qrCodeCommand.Flags().Int32Var(&qrCodeSize, "size", 256, "width and height of the image in pixels")
+	qrCodeCommand.Flags().StringVar(&qrCodeLevel, "level", "M", "error correction level, one of L, M, Q, H")
+}
+
+var qrCodeLevels = map[string]proto.QRCodeLevel{
+	"L": proto.QRCodeLevel_QR_CODE_LEVEL_LOW,
+	"M": proto.QRCodeLevel_QR_CODE_LEVEL_MEDIUM,
+	"Q": proto.QRCodeLevel_QR_CODE_LEVEL_QUARTILE,
+	"H": proto.QRCodeLevel_QR_CODE_LEVEL_HIGH,
+}
+
+var qrCodeUrl string
+var qrCodeOut string
+var qrCodeFormat string
+var qrCodeSize int32
+var qrCodeLevel string
+var qrCodeCommand = &cobra.Command{
+	Use:   "qrcode",
+	Short: "Render QR code of short link",
+	Long:  `gRPC call that'll render QR code of given short link and write it to file'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if qrCodeUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+		if qrCodeOut == "" {
+			fmt.Println("output file wasn't provided")
+			return
+		}
+
+		if qrCodeFormat == "" {
+			qrCodeFormat = strings.TrimPrefix(filepath.Ext(qrCodeOut), ".")
+		}
+		format := proto.QRCodeFormat_QR_CODE_FORMAT_PNG
+		if strings.EqualFold(qrCodeFormat, "svg") {
+			format = proto.QRCodeFormat_QR_CODE_FORMAT_SVG
+		}
+		level, ok := qrCodeLevels[strings.ToUpper(qrCodeLevel)]
+		if !ok {
+			fmt.Printf("unknown error correction level: %s\n", qrCodeLevel)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		code, err := client.GetLinkQRCode(context.Background(), &proto.LinkQRCodeRequest{
+			Link:   qrCodeUrl,
+			Format: format,
+			Size:   qrCodeSize,
+			Level:  level,
+		})
+		if err != nil {
+			fmt.Printf("cannot render qr code: %v\n", err)
+			return
+		}
+
+		if err := os.WriteFile(qrCodeOut, code.GetImage(), 0o644); err != nil {
+			fmt.Printf("cannot write qr code: %v\n", err)
+			return
+		}
+
+		fmt.Printf("qr code (%s) written to %s\n", code.GetContentType(), qrCodeOut)
+	},
+}
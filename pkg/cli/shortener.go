@@ -1,27 +1,48 @@
 package cli
 
 import (
+	"bufio"
 	"challenge/pkg/proto"
 	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
+	"os"
+	"strings"
+	"time"
 )
 
 func init() {
 	rootCmd.AddCommand(shortenerCommand)
 	shortenerCommand.Flags().StringVarP(&url, "url", "u", "", "url that'll be shortened")
+	shortenerCommand.Flags().StringVar(&alias, "alias", "", "custom back-half of the short link")
+	shortenerCommand.Flags().StringVar(&domain, "domain", "", "domain of the short link")
+	shortenerCommand.Flags().StringVar(&group, "group", "", "group guid of the short link")
+	shortenerCommand.Flags().StringVar(&file, "file", "", "file with urls to shorten, one per line ('-' for stdin)")
+	shortenerCommand.Flags().Int64Var(&expiresAt, "expires-at", 0, "unix time when the short link stops working")
+	shortenerCommand.Flags().DurationVar(&ttl, "ttl", 0, "lifetime of the short link, e.g. 72h")
 }
 
+// Amount of urls sent in single batch request
+const batchSize = 500
+
 var url string
+var alias string
+var domain string
+var group string
+var file string
+var expiresAt int64
+var ttl time.Duration
 var shortenerCommand = &cobra.Command{
 	Use:   "shortener",
 	Short: "Shorten link",
 	Long:  `gRPC call that'll shorten given link via Bilty API'`,
 	Run: func(_ *cobra.Command, _ []string) {
 
-		if url == "" {
+		if url == "" && file == "" {
 			fmt.Println("url wasn't provided")
 			return
 		}
@@ -35,7 +56,20 @@ var shortenerCommand = &cobra.Command{
 		}
 
 		client := proto.NewChallengeServiceClient(conn)
-		shortened, err := client.MakeShortLink(context.Background(), &proto.Link{Data: url})
+
+		if file != "" {
+			shortenBatch(client)
+			return
+		}
+
+		shortened, err := client.MakeShortLink(context.Background(), &proto.ShortLinkRequest{
+			Data:       url,
+			Alias:      alias,
+			Domain:     domain,
+			GroupGuid:  group,
+			ExpiresAt:  expiresAt,
+			TtlSeconds: int64(ttl.Seconds()),
+		})
 		if err != nil {
 			fmt.Printf("cannot shorten link: %v\n", err)
 			return
@@ -44,3 +78,58 @@ var shortenerCommand = &cobra.Command{
 		fmt.Printf("shortened link: %s\n", shortened.GetData())
 	},
 }
+
+// shortenBatch reads urls from file or stdin and shortens them with batch requests
+func shortenBatch(client proto.ChallengeServiceClient) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Printf("cannot open file: %v\n", err)
+			return
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var links []*proto.ShortLinkRequest
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		links = append(links, &proto.ShortLinkRequest{
+			Data:       line,
+			Domain:     domain,
+			GroupGuid:  group,
+			ExpiresAt:  expiresAt,
+			TtlSeconds: int64(ttl.Seconds()),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("cannot read urls: %v\n", err)
+		return
+	}
+
+	failed := 0
+	for start := 0; start < len(links); start += batchSize {
+		end := min(start+batchSize, len(links))
+		resp, err := client.MakeShortLinks(context.Background(), &proto.ShortLinkBatchRequest{Links: links[start:end]})
+		if err != nil {
+			fmt.Printf("cannot shorten links: %v\n", err)
+			return
+		}
+
+		for _, result := range resp.GetResults() {
+			if codes.Code(result.GetCode()) != codes.OK {
+				failed++
+				fmt.Printf("%s\terror: %s\n", result.GetData(), result.GetError())
+				continue
+			}
+			fmt.Printf("%s\t%s\n", result.GetData(), result.GetLink())
+		}
+	}
+
+	fmt.Printf("shortened %d of %d links\n", len(links)-failed, len(links))
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"os"
+	"text/tabwriter"
+)
+
+func init() {
+	rootCmd.AddCommand(statsCommand)
+	statsCommand.Flags().StringVarP(&statsUrl, "url", "u", "", "short link to get stats of")
+	statsCommand.Flags().IntVarP(&statsDays, "days", "d", 30, "size of stats window in days, not positive value means all time")
+	statsCommand.Flags().Int64Var(&statsUntil, "until", 0, "unix time of the end of stats window (default: now)")
+	statsCommand.Flags().BoolVar(&statsJson, "json", false, "print stats as json instead of table")
+}
+
+var statsUrl string
+var statsDays int
+var statsUntil int64
+var statsJson bool
+var statsCommand = &cobra.Command{
+	Use:   "stats",
+	Short: "Get link stats",
+	Long:  `gRPC call that'll return total and per-day clicks of given short link'`,
+	Run: func(_ *cobra.Command, _ []string) {
+
+		if statsUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		stats, err := client.GetLinkStats(context.Background(), &proto.LinkStatsRequest{
+			Link:  statsUrl,
+			Days:  int64(statsDays),
+			Until: statsUntil,
+		})
+		if err != nil {
+			fmt.Printf("cannot get link stats: %v\n", err)
+			return
+		}
+
+		if statsJson {
+			bts, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(stats)
+			if err != nil {
+				fmt.Printf("cannot encode stats: %v\n", err)
+				return
+			}
+			fmt.Println(string(bts))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "DATE\tCLICKS\n")
+		for _, day := range stats.GetDays() {
+			fmt.Fprintf(w, "%s\t%d\n", day.GetDate(), day.GetClicks())
+		}
+		fmt.Fprintf(w, "TOTAL\t%d\n", stats.GetTotalClicks())
+		w.Flush()
+	},
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func init() {
+	shortenerCommand.AddCommand(updateCommand)
+	updateCommand.Flags().StringVarP(&updateUrl, "url", "u", "", "short link that'll be updated")
+	updateCommand.Flags().StringVar(&updateTitle, "title", "", "new title of the link")
+	updateCommand.Flags().StringSliceVar(&updateTags, "tag", nil, "new tag of the link, can be repeated, replaces all existing tags")
+	updateCommand.Flags().BoolVar(&updateClearTags, "clear-tags", false, "remove all tags of the link")
+	updateCommand.Flags().StringVar(&updateLongUrl, "long-url", "", "new long url the link points to")
+}
+
+var updateUrl string
+var updateTitle string
+var updateTags []string
+var updateClearTags bool
+var updateLongUrl string
+var updateCommand = &cobra.Command{
+	Use:   "update",
+	Short: "Update short link",
+	Long:  `gRPC call that'll change title, tags or long url of existing short link'`,
+	Run: func(cmd *cobra.Command, _ []string) {
+
+		if updateUrl == "" {
+			fmt.Println("url wasn't provided")
+			return
+		}
+
+		request := &proto.UpdateLinkRequest{
+			Link:      updateUrl,
+			Tags:      updateTags,
+			ClearTags: updateClearTags,
+			LongUrl:   updateLongUrl,
+		}
+		// Empty title is valid value, so it's sent whenever flag is passed
+		if cmd.Flags().Changed("title") {
+			request.Title = &updateTitle
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20)
+		defer cancel()
+		conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fmt.Printf("cannot connect to gRPC server: %v\n", err)
+			return
+		}
+
+		client := proto.NewChallengeServiceClient(conn)
+		updated, err := client.UpdateLink(context.Background(), request)
+		if err != nil {
+			fmt.Printf("cannot update link: %v\n", err)
+			return
+		}
+
+		fmt.Printf("updated link: %s\n", updated.GetData())
+	},
+}
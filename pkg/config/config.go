@@ -4,12 +4,201 @@ import (
 	"errors"
 	"github.com/spf13/viper"
 	"log"
+	"net/http"
 	"os"
+	"time"
+)
+
+const (
+	ShortenerBitly = "bitly"
+	ShortenerLocal = "local"
+
+	StoreMemory = "memory"
+	StoreFile   = "file"
+
+	TimerLocal      = "local"
+	TimerTimercheck = "timercheck"
+
+	SlowPolicyDropOldest = "drop_oldest"
+	SlowPolicyDropNewest = "drop_newest"
+	SlowPolicyCoalesce   = "coalesce"
+	SlowPolicyDisconnect = "disconnect"
 )
 
 type ServerConfig struct {
-	Port            int    `mapstructure:"port"`
-	BitlyOAuthToken string `mapstructure:"BITLY_OAUTH_TOKEN"`
+	Port            int              `mapstructure:"port"`
+	MetricsPort     int              `mapstructure:"metrics_port"`
+	BitlyOAuthToken string           `mapstructure:"BITLY_OAUTH_TOKEN"`
+	Bitly           BitlyConfig      `mapstructure:"bitly"`
+	Timercheck      TimercheckConfig `mapstructure:"timercheck"`
+	Shortener       ShortenerConfig  `mapstructure:"shortener"`
+	Timer           TimerConfig      `mapstructure:"timer"`
+	HttpClient      HttpClientConfig `mapstructure:"http_client"`
+}
+
+// HttpClientConfig configures http clients of upstream APIs, they share connection pool, proxy and CA
+//
+// Not positive values mean defaults, empty ProxyUrl means proxy from environment variables.
+// CAFile is PEM file of certificates trusted in addition to system ones, Debug logs every request
+type HttpClientConfig struct {
+	Timeout             time.Duration `mapstructure:"timeout"`
+	MaxBodyBytes        int64         `mapstructure:"max_body_bytes"`
+	MaxIdleConns        int           `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int           `mapstructure:"max_conns_per_host"`
+	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout"`
+	ProxyUrl            string        `mapstructure:"proxy_url"`
+	CAFile              string        `mapstructure:"ca_file"`
+	Debug               bool          `mapstructure:"debug"`
+}
+
+// UpstreamConfig configures requests to upstream API
+//
+// Empty BaseUrl means public API, Headers are added to every request.
+// Not positive Timeout means timeout of http_client
+type UpstreamConfig struct {
+	BaseUrl   string            `mapstructure:"base_url"`
+	UserAgent string            `mapstructure:"user_agent"`
+	Headers   map[string]string `mapstructure:"headers"`
+	Timeout   time.Duration     `mapstructure:"timeout"`
+}
+
+// TimerConfig selects engine of StartTimer endpoint
+//
+// Local engine keeps timers in server memory, Retention is how long its timed out timers are kept,
+// not positive Retention means one hour. Timercheck engine keeps timers on public timercheck.io.
+// Every subscriber has queue of QueueSize updates, SlowPolicy handles updates which don't fit the queue.
+// Running timer is checked on backend every Resync, not positive Resync means 10 seconds
+type TimerConfig struct {
+	Backend    string        `mapstructure:"backend"`
+	Retention  time.Duration `mapstructure:"retention"`
+	Resync     time.Duration `mapstructure:"resync"`
+	QueueSize  int           `mapstructure:"queue_size"`
+	SlowPolicy string        `mapstructure:"slow_policy"`
+}
+
+// TimercheckConfig configures timercheck.io API client
+type TimercheckConfig struct {
+	Upstream UpstreamConfig `mapstructure:",squash"`
+}
+
+// BitlyConfig sets defaults of created bitlinks, they can be overridden per request,
+// and limits of requests to Bitly API
+//
+// Empty Domain means bit.ly and empty GroupGuid means default group of the token.
+// Expiration enables expiring bitlinks, it must be enabled only for plans which support it
+type BitlyConfig struct {
+	Upstream UpstreamConfig `mapstructure:",squash"`
+
+	Domain     string `mapstructure:"domain"`
+	GroupGuid  string `mapstructure:"group_guid"`
+	Expiration bool   `mapstructure:"expiration"`
+
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Retry     RetryConfig     `mapstructure:"retry"`
+}
+
+// RateLimitConfig is client side limit of API requests, it should match limits of Bitly plan
+//
+// Not positive Requests or Per disable the limit
+type RateLimitConfig struct {
+	Requests int           `mapstructure:"requests"`
+	Per      time.Duration `mapstructure:"per"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// RetryConfig configures retries of failed API requests with exponential backoff
+//
+// Zero MaxRetries disables retries
+type RetryConfig struct {
+	MaxRetries int           `mapstructure:"max_retries"`
+	BaseDelay  time.Duration `mapstructure:"base_delay"`
+	MaxDelay   time.Duration `mapstructure:"max_delay"`
+}
+
+// ShortenerConfig selects backend of MakeShortLink endpoint
+//
+// Fallbacks are tried in order when Backend is unavailable, Breaker configures how long failed backend is skipped
+// BaseUrl, Store, StorePath, HttpPort and RedirectStatus are used only by self-hosted(local) backend
+// HttpPort and RedirectStatus configure http server that resolves self-hosted links
+// BatchConcurrency limits simultaneous backend calls of single MakeShortLinks request
+type ShortenerConfig struct {
+	Backend          string   `mapstructure:"backend"`
+	Fallbacks        []string `mapstructure:"fallbacks"`
+	BatchConcurrency int      `mapstructure:"batch_concurrency"`
+	BaseUrl          string   `mapstructure:"base_url"`
+	Store            string   `mapstructure:"store"`
+	StorePath        string   `mapstructure:"store_path"`
+	HttpPort         int      `mapstructure:"http_port"`
+	RedirectStatus   int      `mapstructure:"redirect_status"`
+
+	Breaker    BreakerConfig    `mapstructure:"breaker"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	Sweep      SweepConfig      `mapstructure:"sweep"`
+	Clicks     ClicksConfig     `mapstructure:"clicks"`
+	Validation ValidationConfig `mapstructure:"validation"`
+	Policy     PolicyConfig     `mapstructure:"policy"`
+}
+
+// BreakerConfig configures circuit breaker of every backend in fallback chain
+//
+// Backend is skipped for Cooldown after Failures consecutive failures, not positive values mean defaults
+type BreakerConfig struct {
+	Failures int           `mapstructure:"failures"`
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// ValidationConfig configures checks of long urls before shortening
+//
+// Empty Schemes allow http and https, not positive MaxLength means no limit
+type ValidationConfig struct {
+	Schemes       []string `mapstructure:"schemes"`
+	MaxLength     int      `mapstructure:"max_length"`
+	RejectPrivate bool     `mapstructure:"reject_private"`
+}
+
+// PolicyConfig configures destination policy, which is checked after validation
+//
+// Blocklist, Allowlist and Patterns are paths of rule files, empty path disables its rules.
+// Files are reloaded on SIGHUP
+type PolicyConfig struct {
+	Blocklist    string             `mapstructure:"blocklist"`
+	Allowlist    string             `mapstructure:"allowlist"`
+	Patterns     string             `mapstructure:"patterns"`
+	Reachability ReachabilityConfig `mapstructure:"reachability"`
+}
+
+// ReachabilityConfig enables HEAD/GET request to destination before shortening
+type ReachabilityConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// SweepConfig configures purge of expired self-hosted links
+//
+// Expired links are kept for Retention, so they respond 410 Gone instead of 404, not positive Interval disables purge
+type SweepConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// ClicksConfig configures persistence of clicks of self-hosted links in file store
+//
+// Clicks are written every Interval or after Batch clicks, whichever comes first, and on shutdown.
+// Not positive Interval disables periodic writes, not positive Batch means default
+type ClicksConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Batch    int           `mapstructure:"batch"`
+}
+
+// CacheConfig configures deduplicating cache in front of shortener backend
+//
+// Not positive TTL and MaxSize mean no limit, they bound persistent layer as well, empty Path disables it
+type CacheConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	TTL     time.Duration `mapstructure:"ttl"`
+	MaxSize int           `mapstructure:"max_size"`
+	Path    string        `mapstructure:"path"`
 }
 
 // MustLoadByPath load envs and marshaling config file in given path
@@ -40,10 +229,73 @@ func MustLoadByPath(path string) *ServerConfig {
 		}
 	}
 
+	// Bitly is default shortener backend
+	if c.Shortener.Backend == "" {
+		c.Shortener.Backend = ShortenerBitly
+	}
+	if c.Shortener.Store == "" {
+		c.Shortener.Store = StoreMemory
+	}
+	if c.Shortener.HttpPort == 0 {
+		c.Shortener.HttpPort = 8080
+	}
+	// Browsers cache permanent redirects, so expiration, archiving, retargeting and clicks would miss repeated visits
+	if c.Shortener.RedirectStatus == 0 {
+		c.Shortener.RedirectStatus = http.StatusFound
+	}
+	if c.Timer.Backend == "" {
+		c.Timer.Backend = TimerLocal
+	}
+	if c.Timer.Backend != TimerLocal && c.Timer.Backend != TimerTimercheck {
+		panic("unknown timer backend: " + c.Timer.Backend)
+	}
+	if c.Timer.SlowPolicy == "" {
+		c.Timer.SlowPolicy = SlowPolicyDropOldest
+	}
+	switch c.Timer.SlowPolicy {
+	case SlowPolicyDropOldest, SlowPolicyDropNewest, SlowPolicyCoalesce, SlowPolicyDisconnect:
+	default:
+		panic("unknown timer slow_policy: " + c.Timer.SlowPolicy)
+	}
+	if c.Shortener.Policy.Reachability.Timeout <= 0 {
+		c.Shortener.Policy.Reachability.Timeout = 5 * time.Second
+	}
+
 	// Check required variables manually
-	if c.BitlyOAuthToken == "" {
-		panic("BITLY_OAUTH_TOKEN is not set")
+	seen := make(map[string]bool)
+	for _, backend := range c.Shortener.Backends() {
+		if seen[backend] {
+			panic("shortener backend is repeated in fallbacks: " + backend)
+		}
+		seen[backend] = true
+
+		switch backend {
+		case ShortenerBitly:
+			if c.BitlyOAuthToken == "" {
+				panic("BITLY_OAUTH_TOKEN is not set")
+			}
+		case ShortenerLocal:
+			if c.Shortener.BaseUrl == "" {
+				panic("shortener.base_url is not set")
+			}
+			if c.Shortener.Store != StoreMemory && c.Shortener.Store != StoreFile {
+				panic("unknown shortener.store: " + c.Shortener.Store)
+			}
+			if c.Shortener.Store == StoreFile && c.Shortener.StorePath == "" {
+				panic("shortener.store_path is not set")
+			}
+			if c.Shortener.RedirectStatus != http.StatusMovedPermanently && c.Shortener.RedirectStatus != http.StatusFound {
+				panic("shortener.redirect_status must be 301 or 302")
+			}
+		default:
+			panic("unknown shortener backend: " + backend)
+		}
 	}
 
 	return &c
 }
+
+// Backends returns primary backend followed by fallbacks
+func (c ShortenerConfig) Backends() []string {
+	return append([]string{c.Backend}, c.Fallbacks...)
+}
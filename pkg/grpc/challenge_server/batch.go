@@ -0,0 +1,56 @@
+package challenge_server
+
+import (
+	"challenge/pkg/proto"
+	"challenge/pkg/shortener"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+)
+
+const (
+	maxBatchSize = 10000
+)
+
+func (s *server) MakeShortLinks(ctx context.Context, in *proto.ShortLinkBatchRequest) (*proto.ShortLinkBatchResponse, error) {
+	if len(in.GetLinks()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "Batch size exceeds %d links", maxBatchSize)
+	}
+
+	results := make([]*proto.ShortLinkResult, len(in.GetLinks()))
+
+	// Semaphore bounds amount of simultaneous calls to shortener backend
+	sem := make(chan struct{}, s.batchConcurrency)
+	wg := sync.WaitGroup{}
+	for i, link := range in.GetLinks() {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, link *proto.ShortLinkRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			linkCtx, backend := shortener.WithBackendRecorder(ctx)
+			short, err := s.makeShortLink(linkCtx, link)
+			st := status.Convert(err)
+			results[i] = &proto.ShortLinkResult{
+				Data:    link.GetData(),
+				Link:    short,
+				Code:    int32(st.Code()),
+				Error:   st.Message(),
+				Backend: backend(),
+			}
+		}(i, link)
+	}
+	wg.Wait()
+
+	return &proto.ShortLinkBatchResponse{Results: results}, nil
+}
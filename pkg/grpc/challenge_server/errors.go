@@ -0,0 +1,134 @@
+package challenge_server
+
+import (
+	"challenge/pkg/api/bilty"
+	"challenge/pkg/shortener"
+	"challenge/pkg/shortener/fallback"
+	"challenge/pkg/urlpolicy"
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	bitlyErrorDomain  = "api-ssl.bitly.com"
+	policyErrorDomain = "shortener"
+	policyReason      = "URL_REJECTED_BY_POLICY"
+)
+
+// invalidArgumentError creates InvalidArgument status error with details of the request field that caused it
+func invalidArgumentError(field string, err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+	return withDetails(st, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: err.Error()},
+		},
+	}).Err()
+}
+
+// policyError converts error of destination policy to gRPC status error
+//
+// Rejected url gets PermissionDenied with the rule that matched in ErrorInfo details
+func policyError(err error) error {
+	var ruleErr *urlpolicy.RuleError
+	switch {
+	case errors.As(err, &ruleErr):
+		st := status.New(codes.PermissionDenied, fmt.Sprintf("Url is rejected by policy rule %s: %s", ruleErr.Rule, ruleErr.Reason))
+		return withDetails(st, &errdetails.ErrorInfo{
+			Reason:   policyReason,
+			Domain:   policyErrorDomain,
+			Metadata: map[string]string{"rule": ruleErr.Rule},
+		}).Err()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+
+	log.Printf("failed to check url policy. err: %v\n", err)
+	return status.Error(codes.Internal, "Failed to check url policy")
+}
+
+// shortenerError converts errors of shortener backends to gRPC status errors
+//
+// Errors of Bitly API are mapped by http status of the response and carry
+// upstream message and description in ErrorInfo details
+func shortenerError(err error) error {
+	var st *status.Status
+	var apiErr *bilty.ApiError
+	switch {
+	// Unavailable chain wraps errors of all backends, including ones which were skipped as unable to serve request
+	case errors.Is(err, fallback.ErrUnavailable):
+		st = status.New(codes.Unavailable, "No shortener backend is available")
+	case errors.Is(err, shortener.ErrAlreadyExists), errors.Is(err, bilty.ErrAliasTaken):
+		st = status.New(codes.AlreadyExists, "Alias is already taken")
+	case errors.Is(err, shortener.ErrNotFound), errors.Is(err, bilty.ErrNotFound):
+		st = status.New(codes.NotFound, "Link not found")
+	case errors.Is(err, shortener.ErrInvalidAlias):
+		st = status.New(codes.InvalidArgument, "Invalid alias")
+	case errors.Is(err, shortener.ErrInvalidDomain):
+		st = status.New(codes.InvalidArgument, "Domain is not supported")
+	case errors.Is(err, shortener.ErrInvalidGroup):
+		st = status.New(codes.InvalidArgument, "Groups are not supported")
+	case errors.Is(err, shortener.ErrInvalidExpiration):
+		st = status.New(codes.InvalidArgument, "Invalid expiration")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, fallback.ErrUnsupported):
+		st = status.New(codes.Unimplemented, "Operation is not supported by shortener")
+	case errors.Is(err, bilty.ErrLinkExists):
+		st = status.New(codes.FailedPrecondition, "Long url is already shortened, expiration can't be set to its link")
+	case errors.Is(err, bilty.ErrUpgradeRequired):
+		st = status.New(codes.FailedPrecondition, "Feature is not available for current Bitly plan")
+	case errors.As(err, &apiErr):
+		st = status.New(apiErrorCode(apiErr.StatusCode), "Bitly API error: "+apiErr.Message)
+	default:
+		return status.Error(codes.Internal, "Failed to get shortened link")
+	}
+
+	if errors.As(err, &apiErr) {
+		st = withDetails(st, &errdetails.ErrorInfo{
+			Reason: apiErr.Message,
+			Domain: bitlyErrorDomain,
+			Metadata: map[string]string{
+				"http_status": strconv.Itoa(apiErr.StatusCode),
+				"description": apiErr.Description,
+			},
+		})
+	}
+
+	return st.Err()
+}
+
+// apiErrorCode maps http status of upstream API response to gRPC code
+func apiErrorCode(httpStatus int) codes.Code {
+	switch {
+	case httpStatus == http.StatusBadRequest, httpStatus == http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case httpStatus == http.StatusForbidden:
+		return codes.PermissionDenied
+	case httpStatus == http.StatusNotFound:
+		return codes.NotFound
+	case httpStatus == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case httpStatus >= 500:
+		return codes.Unavailable
+	}
+
+	return codes.Internal
+}
+
+// withDetails attaches details to status, status is returned as is if details can't be attached
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return detailed
+}
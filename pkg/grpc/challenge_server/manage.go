@@ -0,0 +1,91 @@
+package challenge_server
+
+import (
+	"challenge/pkg/proto"
+	"challenge/pkg/shortener"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+)
+
+func (s *server) UpdateLink(ctx context.Context, in *proto.UpdateLinkRequest) (*proto.Link, error) {
+	var tags []string
+	if in.GetClearTags() {
+		tags = []string{}
+	} else if len(in.GetTags()) != 0 {
+		tags = in.GetTags()
+	}
+	updateMeta := in.Title != nil || tags != nil
+	if !updateMeta && in.GetLongUrl() == "" {
+		return nil, invalidArgumentError("link", errors.New("nothing to update"))
+	}
+
+	editor, ok := shortener.As[LinkEditor](s.shortener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "Changing links is not supported by shortener")
+	}
+
+	longUrl := in.GetLongUrl()
+	if longUrl != "" {
+		if s.validator != nil {
+			validated, err := s.validator.Validate(longUrl)
+			if err != nil {
+				return nil, invalidArgumentError("long_url", err)
+			}
+			longUrl = validated
+		}
+		if err := s.checkPolicy(ctx, longUrl); err != nil {
+			return nil, err
+		}
+	}
+
+	// Long url and metadata are changed together, so request is never applied partially
+	if err := editor.EditLinkContext(ctx, in.GetLink(), longUrl, in.Title, tags); err != nil {
+		log.Printf("failed to update link. err: %v\n", err)
+		return nil, shortenerError(err)
+	}
+	if longUrl != "" {
+		s.invalidate(in.GetLink())
+	}
+
+	return &proto.Link{Data: in.GetLink()}, nil
+}
+
+func (s *server) ArchiveLink(ctx context.Context, in *proto.Link) (*proto.Link, error) {
+	archiver, ok := shortener.As[LinkArchiver](s.shortener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "Link archiving is not supported by shortener")
+	}
+
+	if err := archiver.ArchiveLinkContext(ctx, in.GetData()); err != nil {
+		log.Printf("failed to archive link. err: %v\n", err)
+		return nil, shortenerError(err)
+	}
+	s.invalidate(in.GetData())
+
+	return &proto.Link{Data: in.GetData()}, nil
+}
+
+func (s *server) DeleteLink(ctx context.Context, in *proto.Link) (*proto.Placeholder, error) {
+	deleter, ok := shortener.As[LinkDeleter](s.shortener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "Link deleting is not supported by shortener")
+	}
+
+	if err := deleter.DeleteLinkContext(ctx, in.GetData()); err != nil {
+		log.Printf("failed to delete link. err: %v\n", err)
+		return nil, shortenerError(err)
+	}
+	s.invalidate(in.GetData())
+
+	return &proto.Placeholder{}, nil
+}
+
+// invalidate drops changed link from shortener decorators which remember created links
+func (s *server) invalidate(link string) {
+	if invalidator, ok := shortener.As[LinkInvalidator](s.shortener); ok {
+		invalidator.Invalidate(link)
+	}
+}
@@ -0,0 +1,140 @@
+package challenge_server
+
+import (
+	"challenge/pkg/proto"
+	"challenge/pkg/shortener"
+	"challenge/pkg/shortener/cache"
+	"challenge/pkg/urlcheck"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestUpdateLink_TestCases(t *testing.T) {
+	title := "Campaign"
+
+	tc := []struct {
+		name      string
+		shortener bool
+		request   *proto.UpdateLinkRequest
+		wantLink  shortener.Link
+		wantCode  codes.Code
+	}{
+		{
+			name:     "title and tags",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", Title: &title, Tags: []string{"a", "b"}},
+			wantLink: shortener.Link{Slug: "abc", LongUrl: "https://www.google.com/", Title: "Campaign", Tags: []string{"a", "b"}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "clear tags",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", Tags: []string{"a"}, ClearTags: true},
+			wantLink: shortener.Link{Slug: "abc", LongUrl: "https://www.google.com/", Tags: []string{}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "long url",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", LongUrl: "https://GitHub.com"},
+			wantLink: shortener.Link{Slug: "abc", LongUrl: "https://GitHub.com", Tags: []string{"old"}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "long url with title and tags",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", LongUrl: "https://github.com/", Title: &title, ClearTags: true},
+			wantLink: shortener.Link{Slug: "abc", LongUrl: "https://github.com/", Title: "Campaign", Tags: []string{}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "invalid long url isn't applied with title",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", LongUrl: "ftp://github.com/", Title: &title},
+			wantLink: shortener.Link{Slug: "abc", LongUrl: "https://www.google.com/", Tags: []string{"old"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid long url",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", LongUrl: "ftp://github.com/"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "long url rejected by policy",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", LongUrl: "https://evil.com/"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "nothing to update",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/abc"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown link",
+			request:  &proto.UpdateLinkRequest{Link: "https://sho.rt/unknown", Title: &title},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "not supported",
+			shortener: true,
+			request:   &proto.UpdateLinkRequest{Link: "https://sho.rt/abc", Title: &title},
+			wantCode:  codes.Unimplemented,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			store := shortener.NewMemoryStore()
+			require.NoError(t, store.Save(shortener.Link{Slug: "abc", LongUrl: "https://www.google.com/", Tags: []string{"old"}}))
+			caller := &server{
+				shortener: shortener.NewShortener(store, "https://sho.rt"),
+				validator: urlcheck.NewValidator(urlcheck.Rules{Schemes: []string{"https"}}),
+				policy:    policyMock{blocked: "evil.com"},
+			}
+			if tt.shortener {
+				caller.shortener = shortenerMock{}
+			}
+
+			_, err := caller.UpdateLink(context.Background(), tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantLink.Slug != "" {
+				link, err := store.Get("abc")
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantLink, link)
+			}
+		})
+	}
+}
+
+func TestArchiveAndDeleteLink(t *testing.T) {
+	store := shortener.NewMemoryStore()
+	caller := &server{
+		shortener: cache.NewCache(shortener.NewShortener(store, "https://sho.rt"), time.Hour, 0),
+	}
+
+	created, err := caller.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: "https://www.google.com/"})
+	require.NoError(t, err)
+	slug := created.GetData()[len("https://sho.rt/"):]
+
+	_, err = caller.ArchiveLink(context.Background(), created)
+	require.NoError(t, err)
+	link, err := store.Get(slug)
+	require.NoError(t, err)
+	assert.True(t, link.Archived)
+
+	_, err = caller.DeleteLink(context.Background(), created)
+	require.NoError(t, err)
+	_, err = store.Get(slug)
+	assert.ErrorIs(t, err, shortener.ErrNotFound)
+
+	// Deleted link must not be returned from cache
+	recreated, err := caller.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: "https://www.google.com/"})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.GetData(), recreated.GetData())
+
+	_, err = caller.DeleteLink(context.Background(), created)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = (&server{shortener: shortenerMock{}}).ArchiveLink(context.Background(), created)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
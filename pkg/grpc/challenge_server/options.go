@@ -0,0 +1,34 @@
+package challenge_server
+
+const (
+	defaultBatchConcurrency = 8
+)
+
+// Option configures gRPC server on Register
+type Option func(*server)
+
+// WithBatchConcurrency sets maximum amount of links of single batch
+// which are shortened simultaneously
+func WithBatchConcurrency(n int) Option {
+	return func(s *server) {
+		if n > 0 {
+			s.batchConcurrency = n
+		}
+	}
+}
+
+// WithValidator sets validator of long urls, links with urls rejected by validator
+// are not shortened and InvalidArgument is returned instead
+func WithValidator(v UrlValidator) Option {
+	return func(s *server) {
+		s.validator = v
+	}
+}
+
+// WithPolicy sets destination policy, links with urls rejected by policy
+// are not shortened and PermissionDenied is returned instead
+func WithPolicy(p UrlPolicy) Option {
+	return func(s *server) {
+		s.policy = p
+	}
+}
@@ -0,0 +1,55 @@
+package challenge_server
+
+import (
+	"challenge/pkg/proto"
+	"challenge/pkg/qrcode"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+)
+
+var qrCodeFormats = map[proto.QRCodeFormat]qrcode.Format{
+	proto.QRCodeFormat_QR_CODE_FORMAT_PNG: qrcode.FormatPNG,
+	proto.QRCodeFormat_QR_CODE_FORMAT_SVG: qrcode.FormatSVG,
+}
+
+var qrCodeLevels = map[proto.QRCodeLevel]qrcode.Level{
+	proto.QRCodeLevel_QR_CODE_LEVEL_MEDIUM:   qrcode.LevelMedium,
+	proto.QRCodeLevel_QR_CODE_LEVEL_LOW:      qrcode.LevelLow,
+	proto.QRCodeLevel_QR_CODE_LEVEL_QUARTILE: qrcode.LevelQuartile,
+	proto.QRCodeLevel_QR_CODE_LEVEL_HIGH:     qrcode.LevelHigh,
+}
+
+func (s *server) GetLinkQRCode(_ context.Context, in *proto.LinkQRCodeRequest) (*proto.LinkQRCode, error) {
+	format, ok := qrCodeFormats[in.GetFormat()]
+	if !ok {
+		return nil, invalidArgumentError("format", qrcode.ErrInvalidFormat)
+	}
+	level, ok := qrCodeLevels[in.GetLevel()]
+	if !ok {
+		return nil, invalidArgumentError("level", qrcode.ErrInvalidLevel)
+	}
+
+	image, contentType, err := qrcode.Encode(in.GetLink(), qrcode.Options{
+		Format: format,
+		Size:   int(in.GetSize()),
+		Level:  level,
+	})
+	switch {
+	case errors.Is(err, qrcode.ErrInvalidData):
+		return nil, invalidArgumentError("link", err)
+	case errors.Is(err, qrcode.ErrInvalidSize):
+		return nil, invalidArgumentError("size", err)
+	case err != nil:
+		log.Printf("failed to render qr code. err: %v\n", err)
+		return nil, status.Error(codes.Internal, "Failed to render QR code")
+	}
+
+	return &proto.LinkQRCode{
+		Link:        in.GetLink(),
+		Image:       image,
+		ContentType: contentType,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package challenge_server
+
+import (
+	"challenge/pkg/proto"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestGetLinkQRCode_TestCases(t *testing.T) {
+	tc := []struct {
+		name            string
+		request         *proto.LinkQRCodeRequest
+		wantContentType string
+		wantCode        codes.Code
+	}{
+		{
+			name:            "png",
+			request:         &proto.LinkQRCodeRequest{Link: "https://bit.ly/abc"},
+			wantContentType: "image/png",
+			wantCode:        codes.OK,
+		},
+		{
+			name: "svg",
+			request: &proto.LinkQRCodeRequest{
+				Link:   "https://bit.ly/abc",
+				Format: proto.QRCodeFormat_QR_CODE_FORMAT_SVG,
+				Size:   128,
+				Level:  proto.QRCodeLevel_QR_CODE_LEVEL_HIGH,
+			},
+			wantContentType: "image/svg+xml",
+			wantCode:        codes.OK,
+		},
+		{
+			name:     "empty link",
+			request:  &proto.LinkQRCodeRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid size",
+			request:  &proto.LinkQRCodeRequest{Link: "https://bit.ly/abc", Size: 100000},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown format",
+			request:  &proto.LinkQRCodeRequest{Link: "https://bit.ly/abc", Format: 42},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &server{}
+
+			got, err := caller.GetLinkQRCode(context.Background(), tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.wantContentType, got.GetContentType())
+				assert.NotEmpty(t, got.GetImage())
+			}
+		})
+	}
+}
@@ -2,59 +2,317 @@ package challenge_server
 
 import (
 	"challenge/pkg/proto"
+	"challenge/pkg/shortener"
 	"challenge/pkg/timer"
+	"challenge/pkg/urlnorm"
 	"context"
+	"errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"log"
-	"sync"
+	"sort"
+	"time"
 )
 
-type UrlShortener interface {
-	CreateShortLink(url string) (string, error)
+type UrlShortener = shortener.UrlShortener
+
+// Capabilities of shortener backends take context of gRPC request,
+// so cancellation and deadline of the request reach upstream API
+
+// CustomUrlShortener is implemented by shorteners which are able to create links with custom alias, domain and group
+type CustomUrlShortener interface {
+	CreateCustomShortLinkContext(ctx context.Context, url string, alias string, domain string, group string) (string, error)
+}
+
+// ExpiringUrlShortener is implemented by shorteners which are able to create links that stop working at given moment
+type ExpiringUrlShortener interface {
+	CreateExpiringShortLinkContext(ctx context.Context, url string, alias string, domain string, group string, expiresAt time.Time) (string, error)
+}
+
+// DomainLister is implemented by shorteners which are able to list domains available for links
+type DomainLister interface {
+	ListDomainsContext(ctx context.Context) ([]string, error)
+}
+
+// GroupLister is implemented by shorteners which group links, ListGroupsContext returns group names by their ids
+type GroupLister interface {
+	ListGroupsContext(ctx context.Context) (map[string]string, error)
+}
+
+// ShortLinkExpander is implemented by shorteners which are able to resolve short link back to long url
+type ShortLinkExpander interface {
+	ExpandShortLinkContext(ctx context.Context, url string) (string, error)
+}
+
+// LinkStatsProvider is implemented by shorteners which are able to count clicks of short links
+//
+// GetLinkStatsContext returns total clicks and clicks per day(in YYYY-MM-DD format)
+// over window of given amount of days which ends at until
+type LinkStatsProvider interface {
+	GetLinkStatsContext(ctx context.Context, url string, days int, until time.Time) (int, map[string]int, error)
+}
+
+// LinkEditor is implemented by shorteners which are able to change long url, title and tags of links
+// with single change, so either all of them are changed or none
+//
+// Empty long url and nil title or tags are kept unchanged, empty non-nil tags remove all tags of the link
+type LinkEditor interface {
+	EditLinkContext(ctx context.Context, url string, longUrl string, title *string, tags []string) error
+}
+
+// LinkArchiver is implemented by shorteners which are able to archive links
+type LinkArchiver interface {
+	ArchiveLinkContext(ctx context.Context, url string) error
+}
+
+// LinkDeleter is implemented by shorteners which are able to delete links
+type LinkDeleter interface {
+	DeleteLinkContext(ctx context.Context, url string) error
+}
+
+// LinkInvalidator is implemented by shortener decorators which remember created links,
+// Invalidate is called after link is changed, so stale link is not returned anymore
+type LinkInvalidator interface {
+	Invalidate(url string)
+}
+
+// UrlValidator checks long url before shortening and returns url which is passed to shortener,
+// it is stored as destination, so it must be kept as sent by user
+type UrlValidator interface {
+	Validate(url string) (string, error)
+}
+
+// UrlPolicy decides whether destination of validated long url is safe to be shortened
+type UrlPolicy interface {
+	CheckContext(ctx context.Context, url string) error
 }
 
 const (
 	metadataKey = "i-am-random-key"
+	// backendMetadataKey is response header with name of the backend which produced short link
+	backendMetadataKey = "x-shortener-backend"
 )
 
 type server struct {
 	timer     *timer.Timer
 	shortener UrlShortener
 	proto.UnimplementedChallengeServiceServer
-	mu *sync.Mutex
+
+	batchConcurrency int
+	validator        UrlValidator
+	policy           UrlPolicy
 }
 
-func Register(gRPC *grpc.Server, shortener UrlShortener, timer *timer.Timer) {
-	proto.RegisterChallengeServiceServer(gRPC, &server{shortener: shortener, timer: timer, mu: &sync.Mutex{}})
+func Register(gRPC *grpc.Server, shortener UrlShortener, timer *timer.Timer, opts ...Option) {
+	s := &server{
+		shortener:        shortener,
+		timer:            timer,
+		batchConcurrency: defaultBatchConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	proto.RegisterChallengeServiceServer(gRPC, s)
 }
 
-func (s *server) MakeShortLink(_ context.Context, in *proto.Link) (*proto.Link, error) {
+func (s *server) MakeShortLink(ctx context.Context, in *proto.ShortLinkRequest) (*proto.Link, error) {
+	ctx, backend := shortener.WithBackendRecorder(ctx)
+	link, err := s.makeShortLink(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := backend(); name != "" {
+		// Header is informational, so failure to set it doesn't fail created link
+		if err := grpc.SetHeader(ctx, metadata.Pairs(backendMetadataKey, name)); err != nil {
+			log.Printf("failed to set backend header. err: %v\n", err)
+		}
+	}
+
+	return &proto.Link{Data: link}, nil
+}
+
+// checkPolicy checks canonical form of long url against policy, so blocklist and patterns match
+// however user has written the url, gRPC status error returned when url is rejected
+func (s *server) checkPolicy(ctx context.Context, longUrl string) error {
+	if s.policy == nil {
+		return nil
+	}
+	if normalized, err := urlnorm.Normalize(longUrl); err == nil {
+		longUrl = normalized
+	}
+	if err := s.policy.CheckContext(ctx, longUrl); err != nil {
+		return policyError(err)
+	}
+
+	return nil
+}
+
+// makeShortLink shortens link with options from request and returns gRPC status error on failure
+func (s *server) makeShortLink(ctx context.Context, in *proto.ShortLinkRequest) (string, error) {
+	longUrl := in.GetData()
+	if s.validator != nil {
+		validated, err := s.validator.Validate(longUrl)
+		if err != nil {
+			return "", invalidArgumentError("data", err)
+		}
+		longUrl = validated
+	}
+	if err := s.checkPolicy(ctx, longUrl); err != nil {
+		return "", err
+	}
 
-	link, err := s.shortener.CreateShortLink(in.GetData())
+	expiresAt, err := expiration(in, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var link string
+	switch {
+	case !expiresAt.IsZero():
+		expiring, ok := shortener.As[ExpiringUrlShortener](s.shortener)
+		if !ok {
+			return "", status.Error(codes.Unimplemented, "Expiring links are not supported by shortener")
+		}
+		link, err = expiring.CreateExpiringShortLinkContext(ctx, longUrl, in.GetAlias(), in.GetDomain(), in.GetGroupGuid(), expiresAt)
+	case in.GetAlias() == "" && in.GetDomain() == "" && in.GetGroupGuid() == "":
+		link, err = shortener.CreateShortLinkContext(ctx, s.shortener, longUrl)
+	default:
+		custom, ok := shortener.As[CustomUrlShortener](s.shortener)
+		if !ok {
+			return "", status.Error(codes.Unimplemented, "Custom aliases are not supported by shortener")
+		}
+		link, err = custom.CreateCustomShortLinkContext(ctx, longUrl, in.GetAlias(), in.GetDomain(), in.GetGroupGuid())
+	}
 	if err != nil {
 		log.Printf("failed to get shortened link. err: %v\n", err)
-		return nil, status.Error(codes.Internal, "Failed to get shortened link")
+		return "", shortenerError(err)
 	}
 
-	return &proto.Link{Data: link}, nil
+	return link, nil
 }
 
-func (s *server) StartTimer(timer *proto.Timer, stream proto.ChallengeService_StartTimerServer) error {
+// expiration returns moment when requested link expires, zero time means link never expires
+//
+// Expiration is requested either as absolute unix time or as lifetime, InvalidArgument is returned for both
+func expiration(in *proto.ShortLinkRequest, now time.Time) (time.Time, error) {
+	switch {
+	case in.GetExpiresAt() != 0 && in.GetTtlSeconds() != 0:
+		return time.Time{}, invalidArgumentError("ttl_seconds", errors.New("expires_at and ttl_seconds are mutually exclusive"))
+	case in.GetExpiresAt() != 0:
+		expiresAt := time.Unix(in.GetExpiresAt(), 0)
+		if !expiresAt.After(now) {
+			return time.Time{}, invalidArgumentError("expires_at", errors.New("expires_at is in the past"))
+		}
+		return expiresAt, nil
+	case in.GetTtlSeconds() < 0:
+		return time.Time{}, invalidArgumentError("ttl_seconds", errors.New("ttl_seconds must be positive"))
+	case in.GetTtlSeconds() > 0:
+		return now.Add(time.Duration(in.GetTtlSeconds()) * time.Second), nil
+	}
+
+	return time.Time{}, nil
+}
 
-	// Preventing parallel calls to api. May lead to errors with simultaneous calls
-	s.mu.Lock()
-	ping, err := s.timer.Subscribe(timer.GetName(), int(timer.GetSeconds()), int(timer.GetFrequency()))
+func (s *server) ExpandShortLink(ctx context.Context, in *proto.Link) (*proto.Link, error) {
+	expander, ok := shortener.As[ShortLinkExpander](s.shortener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "Link expanding is not supported by shortener")
+	}
+
+	long, err := expander.ExpandShortLinkContext(ctx, in.GetData())
+	if err != nil {
+		log.Printf("failed to expand link. err: %v\n", err)
+		return nil, shortenerError(err)
+	}
+
+	return &proto.Link{Data: long}, nil
+}
+
+func (s *server) ListLinkDomains(ctx context.Context, _ *proto.Placeholder) (*proto.LinkDomains, error) {
+	domainLister, listsDomains := shortener.As[DomainLister](s.shortener)
+	groupLister, listsGroups := shortener.As[GroupLister](s.shortener)
+	if !listsDomains && !listsGroups {
+		return nil, status.Error(codes.Unimplemented, "Listing of domains is not supported by shortener")
+	}
+
+	resp := &proto.LinkDomains{}
+	if listsDomains {
+		domains, err := domainLister.ListDomainsContext(ctx)
+		if err != nil {
+			log.Printf("failed to list domains. err: %v\n", err)
+			return nil, shortenerError(err)
+		}
+		resp.Domains = domains
+	}
+
+	if listsGroups {
+		groups, err := groupLister.ListGroupsContext(ctx)
+		if err != nil {
+			log.Printf("failed to list groups. err: %v\n", err)
+			return nil, shortenerError(err)
+		}
+		for guid, name := range groups {
+			resp.Groups = append(resp.Groups, &proto.LinkGroup{Guid: guid, Name: name})
+		}
+		sort.Slice(resp.Groups, func(i, j int) bool {
+			return resp.Groups[i].Name < resp.Groups[j].Name
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *server) GetLinkStats(ctx context.Context, in *proto.LinkStatsRequest) (*proto.LinkStats, error) {
+	provider, ok := shortener.As[LinkStatsProvider](s.shortener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "Link stats are not supported by shortener")
+	}
+
+	until := time.Now()
+	if in.GetUntil() != 0 {
+		until = time.Unix(in.GetUntil(), 0)
+	}
+
+	total, daily, err := provider.GetLinkStatsContext(ctx, in.GetLink(), int(in.GetDays()), until)
+	if err != nil {
+		log.Printf("failed to get link stats. err: %v\n", err)
+		return nil, shortenerError(err)
+	}
+
+	stats := &proto.LinkStats{
+		Link:        in.GetLink(),
+		TotalClicks: int64(total),
+		Days:        make([]*proto.DailyClicks, 0, len(daily)),
+	}
+	for date, clicks := range daily {
+		stats.Days = append(stats.Days, &proto.DailyClicks{Date: date, Clicks: int64(clicks)})
+	}
+	// Dates are in YYYY-MM-DD format, so lexical order is chronological
+	sort.Slice(stats.Days, func(i, j int) bool {
+		return stats.Days[i].Date < stats.Days[j].Date
+	})
+
+	return stats, nil
+}
+
+func (s *server) StartTimer(in *proto.Timer, stream proto.ChallengeService_StartTimerServer) error {
+
+	// Subscriptions of the same timer are serialized by timer, so simultaneous calls don't create it twice
+	ping, err := s.timer.SubscribeContext(stream.Context(), in.GetName(), int(in.GetSeconds()), int(in.GetFrequency()))
 	if err != nil {
 		log.Println("error when subscribing to timer: ", err)
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
 		return status.Error(codes.Internal, "Couldn't start or subscribe to timer")
 	}
-	s.mu.Unlock()
 
 	defer func() {
-		s.timer.Unsubscribe(timer.GetName(), ping)
+		_ = s.timer.Unsubscribe(in.GetName(), ping)
 		log.Println("ending streaming grpc method")
 	}()
 
@@ -66,13 +324,17 @@ func (s *server) StartTimer(timer *proto.Timer, stream proto.ChallengeService_St
 		case info, ok := <-ping:
 			if !ok {
 				log.Println("ping channel was closed")
+				// Channel is also closed when stream doesn't keep up with timer updates
+				if err := s.timer.Unsubscribe(in.GetName(), ping); errors.Is(err, timer.ErrSlowConsumer) {
+					return status.Error(codes.ResourceExhausted, "Stream is too slow to receive timer updates")
+				}
 				return nil
 			}
 
 			err = stream.Send(&proto.Timer{
 				Name:      info.TimerName,
 				Seconds:   int64(info.SecondsLeft),
-				Frequency: timer.Frequency,
+				Frequency: in.Frequency,
 			})
 			if err != nil {
 				log.Printf("failed to send message to stream. err: %v\n", err)
@@ -1,13 +1,49 @@
 package challenge_server
 
 import (
+	"challenge/pkg/api/bilty"
 	"challenge/pkg/proto"
+	"challenge/pkg/shortener"
+	"challenge/pkg/shortener/fallback"
+	"challenge/pkg/urlcheck"
+	"challenge/pkg/urlpolicy"
 	"context"
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+type shortenerMock struct {
+	link string
+	err  error
+}
+
+func (s shortenerMock) CreateShortLink(_ string) (string, error) {
+	return s.link, s.err
+}
+
+type customShortenerMock struct {
+	shortenerMock
+}
+
+func (s customShortenerMock) CreateCustomShortLinkContext(_ context.Context, _ string, alias string, _ string, _ string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.link + alias, nil
+}
+
 func TestReadMetadata_TestCases(t *testing.T) {
 	tc := []struct {
 		name           string
@@ -58,3 +94,592 @@ func TestReadMetadata_TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeShortLink_TestCases(t *testing.T) {
+	tc := []struct {
+		name      string
+		shortener UrlShortener
+		request   *proto.ShortLinkRequest
+		want      string
+		wantCode  codes.Code
+	}{
+		{
+			name:      "ok",
+			shortener: shortenerMock{link: "https://sho.rt/abc"},
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/"},
+			want:      "https://sho.rt/abc",
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "ok, with alias",
+			shortener: customShortenerMock{shortenerMock{link: "https://sho.rt/"}},
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/", Alias: "custom"},
+			want:      "https://sho.rt/custom",
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "alias not supported",
+			shortener: shortenerMock{link: "https://sho.rt/abc"},
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/", Alias: "custom"},
+			wantCode:  codes.Unimplemented,
+		},
+		{
+			name:      "alias taken",
+			shortener: customShortenerMock{shortenerMock{err: shortener.ErrAlreadyExists}},
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/", Alias: "custom"},
+			wantCode:  codes.AlreadyExists,
+		},
+		{
+			name:      "backend failure",
+			shortener: shortenerMock{err: errors.New("something goes wrong")},
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/"},
+			wantCode:  codes.Internal,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &server{shortener: tt.shortener}
+
+			got, err := caller.MakeShortLink(context.Background(), tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.want, got.GetData())
+			}
+		})
+	}
+}
+
+type expanderMock struct {
+	shortenerMock
+}
+
+func (s expanderMock) ExpandShortLinkContext(_ context.Context, _ string) (string, error) {
+	return s.link, s.err
+}
+
+func TestExpandShortLink_TestCases(t *testing.T) {
+	tc := []struct {
+		name      string
+		shortener UrlShortener
+		want      string
+		wantCode  codes.Code
+	}{
+		{
+			name:      "ok",
+			shortener: expanderMock{shortenerMock{link: "https://www.google.com/"}},
+			want:      "https://www.google.com/",
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "not supported",
+			shortener: shortenerMock{},
+			wantCode:  codes.Unimplemented,
+		},
+		{
+			name:      "not found",
+			shortener: expanderMock{shortenerMock{err: shortener.ErrNotFound}},
+			wantCode:  codes.NotFound,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &server{shortener: tt.shortener}
+
+			got, err := caller.ExpandShortLink(context.Background(), &proto.Link{Data: "https://sho.rt/abc"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.want, got.GetData())
+			}
+		})
+	}
+}
+
+type statsMock struct {
+	shortenerMock
+	total int
+	daily map[string]int
+}
+
+func (s statsMock) GetLinkStatsContext(_ context.Context, _ string, _ int, _ time.Time) (int, map[string]int, error) {
+	return s.total, s.daily, s.err
+}
+
+func TestGetLinkStats_TestCases(t *testing.T) {
+	tc := []struct {
+		name      string
+		shortener UrlShortener
+		wantDates []string
+		wantCode  codes.Code
+	}{
+		{
+			name: "ok, sorted by date",
+			shortener: statsMock{
+				total: 6,
+				daily: map[string]int{"2024-03-02": 1, "2024-02-29": 2, "2024-03-01": 3},
+			},
+			wantDates: []string{"2024-02-29", "2024-03-01", "2024-03-02"},
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "not supported",
+			shortener: shortenerMock{},
+			wantCode:  codes.Unimplemented,
+		},
+		{
+			name:      "not found",
+			shortener: statsMock{shortenerMock: shortenerMock{err: shortener.ErrNotFound}},
+			wantCode:  codes.NotFound,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &server{shortener: tt.shortener}
+
+			got, err := caller.GetLinkStats(context.Background(), &proto.LinkStatsRequest{Link: "https://sho.rt/abc", Days: 3})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.EqualValues(t, 6, got.GetTotalClicks())
+				var dates []string
+				for _, d := range got.GetDays() {
+					dates = append(dates, d.GetDate())
+				}
+				assert.Equal(t, tt.wantDates, dates)
+			}
+		})
+	}
+}
+
+// concurrencyMock tracks maximum amount of simultaneous calls
+type concurrencyMock struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (s *concurrencyMock) CreateShortLink(url string) (string, error) {
+	s.mu.Lock()
+	s.current++
+	s.max = max(s.max, s.current)
+	s.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	s.mu.Lock()
+	s.current--
+	s.mu.Unlock()
+
+	if url == "bad" {
+		return "", errors.New("something goes wrong")
+	}
+	return "https://sho.rt/" + url, nil
+}
+
+func TestMakeShortLinks_TestCases(t *testing.T) {
+	mock := &concurrencyMock{}
+	caller := &server{shortener: mock, batchConcurrency: 3}
+
+	var links []*proto.ShortLinkRequest
+	for i := 0; i < 50; i++ {
+		links = append(links, &proto.ShortLinkRequest{Data: fmt.Sprintf("%d", i)})
+	}
+	links = append(links, &proto.ShortLinkRequest{Data: "bad"}, &proto.ShortLinkRequest{Data: "custom", Alias: "alias"})
+
+	got, err := caller.MakeShortLinks(context.Background(), &proto.ShortLinkBatchRequest{Links: links})
+	require.NoError(t, err)
+	require.Len(t, got.GetResults(), len(links))
+
+	for i := 0; i < 50; i++ {
+		result := got.GetResults()[i]
+		assert.Equal(t, fmt.Sprintf("%d", i), result.GetData())
+		assert.Equal(t, fmt.Sprintf("https://sho.rt/%d", i), result.GetLink())
+		assert.EqualValues(t, codes.OK, result.GetCode())
+	}
+	assert.EqualValues(t, codes.Internal, got.GetResults()[50].GetCode())
+	assert.EqualValues(t, codes.Unimplemented, got.GetResults()[51].GetCode())
+	assert.NotEmpty(t, got.GetResults()[51].GetError())
+
+	assert.LessOrEqual(t, mock.max, 3)
+}
+
+func TestMakeShortLinks_TooLarge(t *testing.T) {
+	caller := &server{shortener: shortenerMock{}, batchConcurrency: 1}
+
+	links := make([]*proto.ShortLinkRequest, maxBatchSize+1)
+	_, err := caller.MakeShortLinks(context.Background(), &proto.ShortLinkBatchRequest{Links: links})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// recordingShortener remembers url it was called with
+type recordingShortener struct {
+	got string
+}
+
+func (s *recordingShortener) CreateShortLink(url string) (string, error) {
+	s.got = url
+	return "https://sho.rt/abc", nil
+}
+
+func TestMakeShortLink_Validation(t *testing.T) {
+	tc := []struct {
+		name     string
+		url      string
+		wantUrl  string
+		wantCode codes.Code
+	}{
+		{
+			name:     "url passed to shortener as sent",
+			url:      "HTTPS://WWW.GOOGLE.COM/search?q=go;lang=en&flag#results",
+			wantUrl:  "HTTPS://WWW.GOOGLE.COM/search?q=go;lang=en&flag#results",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "empty url",
+			url:      "",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "not a url",
+			url:      "not a url",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "private host",
+			url:      "http://127.0.0.1/",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &recordingShortener{}
+			caller := &server{shortener: backend, validator: urlcheck.NewValidator(urlcheck.Rules{RejectPrivate: true})}
+
+			_, err := caller.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: tt.url})
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.wantUrl, backend.got)
+				return
+			}
+
+			// Rejected url must never reach shortener
+			assert.Empty(t, backend.got)
+			details := status.Convert(err).Details()
+			require.Len(t, details, 1)
+			badRequest, ok := details[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			require.Len(t, badRequest.GetFieldViolations(), 1)
+			assert.Equal(t, "data", badRequest.GetFieldViolations()[0].GetField())
+		})
+	}
+}
+
+// policyMock rejects urls of blocked host
+type policyMock struct {
+	blocked string
+}
+
+func (p policyMock) CheckContext(_ context.Context, url string) error {
+	if strings.Contains(url, p.blocked) {
+		return &urlpolicy.RuleError{Rule: "blocklist:" + p.blocked, Reason: "host is blocked"}
+	}
+	return nil
+}
+
+func TestMakeShortLink_Policy(t *testing.T) {
+	backend := &recordingShortener{}
+	caller := &server{
+		shortener: backend,
+		validator: urlcheck.NewValidator(urlcheck.Rules{}),
+		policy:    policyMock{blocked: "evil.com"},
+	}
+
+	_, err := caller.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: "https://www.google.com/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/", backend.got)
+
+	backend.got = ""
+	_, err = caller.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: "HTTPS://EVIL.COM/login"})
+	st := status.Convert(err)
+	require.Equal(t, codes.PermissionDenied, st.Code())
+	assert.Contains(t, st.Message(), "blocklist:evil.com")
+	assert.Empty(t, backend.got)
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "blocklist:evil.com", info.GetMetadata()["rule"])
+}
+
+func TestShortenerError_TestCases(t *testing.T) {
+	tc := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name:       "bad request",
+			err:        &bilty.ApiError{StatusCode: http.StatusBadRequest, Message: "INVALID_ARG_LONG_URL"},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "INVALID_ARG_LONG_URL",
+		},
+		{
+			name:       "forbidden",
+			err:        &bilty.ApiError{StatusCode: http.StatusForbidden, Message: "FORBIDDEN"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: "FORBIDDEN",
+		},
+		{
+			name:       "rate limited",
+			err:        &bilty.ApiError{StatusCode: http.StatusTooManyRequests, Message: "RATE_LIMIT_EXCEEDED"},
+			wantCode:   codes.ResourceExhausted,
+			wantReason: "RATE_LIMIT_EXCEEDED",
+		},
+		{
+			name:       "upstream unavailable",
+			err:        &bilty.ApiError{StatusCode: http.StatusServiceUnavailable, Message: "TEMPORARILY_UNAVAILABLE"},
+			wantCode:   codes.Unavailable,
+			wantReason: "TEMPORARILY_UNAVAILABLE",
+		},
+		{
+			name:       "wrapped with sentinel",
+			err:        fmt.Errorf("%w: %w", bilty.ErrAliasTaken, &bilty.ApiError{StatusCode: http.StatusConflict, Message: "ALREADY_EXISTS"}),
+			wantCode:   codes.AlreadyExists,
+			wantReason: "ALREADY_EXISTS",
+		},
+		{
+			name:     "existing bitlink can't expire",
+			err:      fmt.Errorf("%w: %v", bilty.ErrLinkExists, "expiration can't be set to existing bitlink bit.ly/abc"),
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "canceled request",
+			err:      fmt.Errorf("%w: %w", bilty.ErrInternal, context.Canceled),
+			wantCode: codes.Canceled,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      fmt.Errorf("%w: %w", bilty.ErrInternal, context.DeadlineExceeded),
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name:     "all backends unavailable",
+			err:      fmt.Errorf("%w: %w", fallback.ErrUnavailable, errors.New("connection refused")),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "unavailable with backend unable to serve request",
+			err:      fmt.Errorf("%w: %w", fallback.ErrUnavailable, errors.Join(errors.New("connection refused"), shortener.ErrInvalidGroup)),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "unknown error",
+			err:      errors.New("something goes wrong"),
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(shortenerError(tt.err))
+			assert.Equal(t, tt.wantCode, st.Code())
+
+			if tt.wantReason == "" {
+				assert.Empty(t, st.Details())
+				return
+			}
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReason, info.GetReason())
+			assert.NotEmpty(t, info.GetMetadata()["http_status"])
+		})
+	}
+}
+
+type domainsMock struct {
+	shortenerMock
+	domains []string
+	groups  map[string]string
+}
+
+func (s domainsMock) ListDomainsContext(_ context.Context) ([]string, error) {
+	return s.domains, s.err
+}
+
+func (s domainsMock) ListGroupsContext(_ context.Context) (map[string]string, error) {
+	return s.groups, s.err
+}
+
+func TestListLinkDomains_TestCases(t *testing.T) {
+	tc := []struct {
+		name       string
+		shortener  UrlShortener
+		wantGroups []string
+		wantCode   codes.Code
+	}{
+		{
+			name: "ok, sorted groups",
+			shortener: domainsMock{
+				domains: []string{"bit.ly", "brand.co"},
+				groups:  map[string]string{"Bb2": "support", "Ba1": "marketing"},
+			},
+			wantGroups: []string{"marketing", "support"},
+			wantCode:   codes.OK,
+		},
+		{
+			name:      "not supported",
+			shortener: shortenerMock{},
+			wantCode:  codes.Unimplemented,
+		},
+		{
+			name:      "upstream error",
+			shortener: domainsMock{shortenerMock: shortenerMock{err: &bilty.ApiError{StatusCode: http.StatusForbidden}}},
+			wantCode:  codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := &server{shortener: tt.shortener}
+
+			got, err := caller.ListLinkDomains(context.Background(), &proto.Placeholder{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, []string{"bit.ly", "brand.co"}, got.GetDomains())
+				var names []string
+				for _, g := range got.GetGroups() {
+					names = append(names, g.GetName())
+				}
+				assert.Equal(t, tt.wantGroups, names)
+			}
+		})
+	}
+}
+
+type requestKey struct{}
+
+// contextShortenerMock reports context it was called with
+type contextShortenerMock struct {
+	shortenerMock
+	got chan context.Context
+}
+
+func (s contextShortenerMock) CreateShortLinkContext(ctx context.Context, _ string) (string, error) {
+	s.got <- ctx
+	return "", ctx.Err()
+}
+
+func TestMakeShortLink_ContextPropagation(t *testing.T) {
+	mock := contextShortenerMock{got: make(chan context.Context, 1)}
+	caller := &server{shortener: mock}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestKey{}, "request"))
+	cancel()
+
+	_, err := caller.MakeShortLink(ctx, &proto.ShortLinkRequest{Data: "https://www.google.com/"})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, "request", (<-mock.got).Value(requestKey{}))
+}
+
+// transportStreamMock captures headers set by handler
+type transportStreamMock struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *transportStreamMock) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestMakeShortLink_Backend(t *testing.T) {
+	chain := fallback.NewChain([]fallback.Backend{
+		{Name: "bitly", Shortener: shortenerMock{err: &bilty.ApiError{StatusCode: http.StatusServiceUnavailable}}},
+		{Name: "local", Shortener: shortenerMock{link: "https://sho.rt/abc"}},
+	})
+	caller := &server{shortener: chain, batchConcurrency: 1}
+
+	stream := &transportStreamMock{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	got, err := caller.MakeShortLink(ctx, &proto.ShortLinkRequest{Data: "https://www.google.com/"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://sho.rt/abc", got.GetData())
+	assert.Equal(t, []string{"local"}, stream.header.Get(backendMetadataKey))
+
+	batch, err := caller.MakeShortLinks(context.Background(), &proto.ShortLinkBatchRequest{
+		Links: []*proto.ShortLinkRequest{{Data: "https://www.google.com/"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.GetResults(), 1)
+	assert.Equal(t, "local", batch.GetResults()[0].GetBackend())
+}
+
+func TestMakeShortLink_Expiration(t *testing.T) {
+	now := time.Now()
+
+	tc := []struct {
+		name      string
+		shortener bool
+		request   *proto.ShortLinkRequest
+		wantTTL   time.Duration
+		wantCode  codes.Code
+	}{
+		{
+			name:     "ttl",
+			request:  &proto.ShortLinkRequest{Data: "https://www.google.com/", TtlSeconds: 3600},
+			wantTTL:  time.Hour,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "absolute time",
+			request:  &proto.ShortLinkRequest{Data: "https://www.google.com/", Alias: "campaign", ExpiresAt: now.Add(2 * time.Hour).Unix()},
+			wantTTL:  2 * time.Hour,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "in the past",
+			request:  &proto.ShortLinkRequest{Data: "https://www.google.com/", ExpiresAt: now.Add(-time.Hour).Unix()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "negative ttl",
+			request:  &proto.ShortLinkRequest{Data: "https://www.google.com/", TtlSeconds: -1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "both time and ttl",
+			request:  &proto.ShortLinkRequest{Data: "https://www.google.com/", TtlSeconds: 60, ExpiresAt: now.Add(time.Hour).Unix()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:      "not supported",
+			shortener: true,
+			request:   &proto.ShortLinkRequest{Data: "https://www.google.com/", TtlSeconds: 60},
+			wantCode:  codes.Unimplemented,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			store := shortener.NewMemoryStore()
+			caller := &server{shortener: shortener.NewShortener(store, "https://sho.rt")}
+			if tt.shortener {
+				caller.shortener = shortenerMock{link: "https://sho.rt/abc"}
+			}
+
+			got, err := caller.MakeShortLink(context.Background(), tt.request)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				return
+			}
+
+			link, err := store.Get(strings.TrimPrefix(got.GetData(), "https://sho.rt/"))
+			require.NoError(t, err)
+			assert.WithinDuration(t, now.Add(tt.wantTTL), link.ExpiresAt, 2*time.Second)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Package httpclient creates http clients for upstream APIs
+//
+// Clients of all upstreams share connection pool, proxy and TLS settings of the factory,
+// while timeout is set per upstream. Response bodies are limited in size and drained on close,
+// so connections are returned to the pool even when caller doesn't read the body
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+var (
+	ErrInternal     = errors.New("internal error")
+	ErrBodyTooLarge = errors.New("response body is too large")
+)
+
+const (
+	defaultTimeout             = 10 * time.Second
+	defaultMaxBodyBytes        = 1 << 20
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+
+	// maxDrainBytes is read from unread body on close, larger bodies are dropped with their connection
+	maxDrainBytes = 64 << 10
+)
+
+// Config configures clients created by factory
+//
+// Not positive values mean defaults, except MaxConnsPerHost where it means no limit.
+// Empty ProxyUrl means proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// CAFile is PEM file with certificates trusted in addition to system ones.
+// Debug logs every request with its status and duration
+type Config struct {
+	Timeout             time.Duration
+	MaxBodyBytes        int64
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	ProxyUrl            string
+	CAFile              string
+	Debug               bool
+}
+
+// Factory creates clients which share single transport
+type Factory struct {
+	transport    *http.Transport
+	timeout      time.Duration
+	maxBodyBytes int64
+	debug        bool
+	logger       *log.Logger
+}
+
+// Option configures Factory on creation
+type Option func(*Factory)
+
+// WithLogger sets logger of debug logging, standard logger is used by default
+func WithLogger(logger *log.Logger) Option {
+	return func(f *Factory) {
+		f.logger = logger
+	}
+}
+
+// NewFactory creates factory with transport configured by cfg
+//
+// ErrInternal returned when proxy url or CA file is not valid
+func NewFactory(cfg Config, opts ...Option) (*Factory, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = positive(cfg.MaxIdleConns, defaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = positive(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost)
+	transport.MaxConnsPerHost = max(cfg.MaxConnsPerHost, 0)
+	transport.IdleConnTimeout = positive(cfg.IdleConnTimeout, defaultIdleConnTimeout)
+
+	if cfg.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(cfg.ProxyUrl)
+		if err != nil || proxyUrl.Host == "" {
+			return nil, fmt.Errorf("%w: proxy url %q is not valid", ErrInternal, cfg.ProxyUrl)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	f := &Factory{
+		transport:    transport,
+		timeout:      positive(cfg.Timeout, defaultTimeout),
+		maxBodyBytes: positive(cfg.MaxBodyBytes, defaultMaxBodyBytes),
+		debug:        cfg.Debug,
+		logger:       log.Default(),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f, nil
+}
+
+// Client creates client of upstream with given name, which is used in debug logs
+//
+// Not positive timeout means timeout of the factory
+func (f *Factory) Client(name string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: positive(timeout, f.timeout),
+		Transport: &transport{
+			name:         name,
+			next:         f.transport,
+			maxBodyBytes: f.maxBodyBytes,
+			debug:        f.debug,
+			logger:       f.logger,
+		},
+	}
+}
+
+// CloseIdleConnections closes idle connections of all clients of the factory
+func (f *Factory) CloseIdleConnections() {
+	f.transport.CloseIdleConnections()
+}
+
+// transport limits and drains response bodies and logs requests of single upstream
+type transport struct {
+	name         string
+	next         http.RoundTripper
+	maxBodyBytes int64
+	debug        bool
+	logger       *log.Logger
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if t.debug {
+		// Only method and url are logged, headers may contain credentials
+		target := req.URL.Redacted()
+		if err != nil {
+			t.logger.Printf("http %s: %s %s failed in %v. err: %v\n", t.name, req.Method, target, time.Since(start), err)
+		} else {
+			t.logger.Printf("http %s: %s %s %d in %v\n", t.name, req.Method, target, resp.StatusCode, time.Since(start))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &body{ReadCloser: resp.Body, limit: t.maxBodyBytes, remaining: t.maxBodyBytes}
+	return resp, nil
+}
+
+// body fails reads past the limit and drains unread rest of the body on close
+type body struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Limit is reached, body is too large only if there is more data
+		n, err := b.ReadCloser.Read(make([]byte, 1))
+		if n > 0 {
+			return 0, fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, b.limit)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *body) Close() error {
+	_, _ = io.CopyN(io.Discard, b.ReadCloser, maxDrainBytes)
+	return b.ReadCloser.Close()
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: no certificates in %s", ErrInternal, path)
+	}
+
+	return pool, nil
+}
+
+func positive[T int | int64 | time.Duration](value T, fallback T) T {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
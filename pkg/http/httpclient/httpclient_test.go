@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_BodyLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 10)))
+	}))
+	defer srv.Close()
+
+	tc := []struct {
+		name         string
+		maxBodyBytes int64
+		wantErr      bool
+	}{
+		{name: "under limit", maxBodyBytes: 11},
+		{name: "exactly limit", maxBodyBytes: 10},
+		{name: "over limit", maxBodyBytes: 9, wantErr: true},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFactory(Config{MaxBodyBytes: tt.maxBodyBytes})
+			require.NoError(t, err)
+
+			resp, err := f.Client("test", 0).Get(srv.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrBodyTooLarge)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, body, 10)
+		})
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	f, err := NewFactory(Config{Timeout: time.Hour})
+	require.NoError(t, err)
+
+	// Timeout of upstream overrides timeout of the factory
+	_, err = f.Client("test", 50*time.Millisecond).Get(srv.URL)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestClient_DrainsBody(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 4<<10)))
+	}))
+	var conns atomic.Int32
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	f, err := NewFactory(Config{})
+	require.NoError(t, err)
+	client := f.Client("test", 0)
+
+	// Unread bodies are drained on close, so every request reuses the same connection
+	for range 5 {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	assert.Equal(t, int32(1), conns.Load())
+}
+
+func TestClient_Proxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+	}))
+	defer proxy.Close()
+
+	f, err := NewFactory(Config{ProxyUrl: proxy.URL})
+	require.NoError(t, err)
+
+	resp, err := f.Client("test", 0).Get("http://upstream.example/v4/shorten")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "http://upstream.example/v4/shorten", proxied.Load())
+
+	_, err = NewFactory(Config{ProxyUrl: "not a url"})
+	assert.ErrorIs(t, err, ErrInternal)
+}
+
+func TestClient_CAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	f, err := NewFactory(Config{})
+	require.NoError(t, err)
+	_, err = f.Client("test", 0).Get(srv.URL)
+	require.Error(t, err, "certificate of test server must not be trusted by default")
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(path, certPem, 0o600))
+
+	f, err = NewFactory(Config{CAFile: path})
+	require.NoError(t, err)
+	resp, err := f.Client("test", 0).Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = NewFactory(Config{CAFile: path})
+	assert.ErrorIs(t, err, ErrInternal)
+}
+
+func TestClient_Debug(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	tc := []struct {
+		name    string
+		debug   bool
+		wantLog string
+	}{
+		{name: "enabled", debug: true, wantLog: "http bitly: GET " + srv.URL + "/path 418 in "},
+		{name: "disabled", debug: false},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f, err := NewFactory(Config{Debug: tt.debug}, WithLogger(log.New(&buf, "", 0)))
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/path", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer secret")
+			resp, err := f.Client("bitly", 0).Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if tt.wantLog == "" {
+				assert.Empty(t, buf.String())
+				return
+			}
+			assert.Contains(t, buf.String(), tt.wantLog)
+			assert.NotContains(t, buf.String(), "secret")
+		})
+	}
+}
+
+func TestBody_ReadAfterLimitAtEOF(t *testing.T) {
+	b := &body{ReadCloser: io.NopCloser(strings.NewReader("abc")), limit: 3, remaining: 3}
+
+	data, err := io.ReadAll(b)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	_, err = b.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, io.EOF))
+}
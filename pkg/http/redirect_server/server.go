@@ -0,0 +1,60 @@
+package redirect_server
+
+import (
+	"challenge/pkg/shortener"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+type LinkStore interface {
+	Get(slug string) (shortener.Link, error)
+	RecordClick(slug string, at time.Time) error
+}
+
+type server struct {
+	store          LinkStore
+	redirectStatus int
+}
+
+// NewHandler creates http handler that resolves self-hosted short links
+//
+// GET /{slug} redirects to long url of the link with given redirect status(302 or 301, which is cached by browsers),
+// responds 404 for unknown slugs and 410 for expired or archived links. Every redirect is counted as a click
+func NewHandler(store LinkStore, redirectStatus int) http.Handler {
+	s := &server{store: store, redirectStatus: redirectStatus}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{slug}", s.Redirect)
+
+	return mux
+}
+
+func (s *server) Redirect(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	link, err := s.store.Get(slug)
+	if errors.Is(err, shortener.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to get link from store. err: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	if link.Expired(now) || link.Archived {
+		http.Error(w, http.StatusText(http.StatusGone), http.StatusGone)
+		return
+	}
+
+	// Failed click recording must not break redirect
+	if err := s.store.RecordClick(slug, now); err != nil {
+		log.Printf("failed to record click. err: %v\n", err)
+	}
+
+	http.Redirect(w, r, link.LongUrl, s.redirectStatus)
+}
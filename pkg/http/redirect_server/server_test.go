@@ -0,0 +1,109 @@
+package redirect_server
+
+import (
+	"challenge/pkg/shortener"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRedirect_TestCases(t *testing.T) {
+	store := shortener.NewMemoryStore()
+	require.NoError(t, store.Save(shortener.Link{Slug: "active", LongUrl: "https://www.google.com/"}))
+	require.NoError(t, store.Save(shortener.Link{
+		Slug:      "expired",
+		LongUrl:   "https://www.google.com/",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	require.NoError(t, store.Save(shortener.Link{Slug: "archived", LongUrl: "https://www.google.com/", Archived: true}))
+
+	tc := []struct {
+		name           string
+		method         string
+		path           string
+		redirectStatus int
+		wantStatus     int
+		wantLocation   string
+	}{
+		{
+			name:           "ok, permanent",
+			method:         http.MethodGet,
+			path:           "/active",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusMovedPermanently,
+			wantLocation:   "https://www.google.com/",
+		},
+		{
+			name:           "ok, temporary",
+			method:         http.MethodGet,
+			path:           "/active",
+			redirectStatus: http.StatusFound,
+			wantStatus:     http.StatusFound,
+			wantLocation:   "https://www.google.com/",
+		},
+		{
+			name:           "unknown slug",
+			method:         http.MethodGet,
+			path:           "/unknown",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusNotFound,
+		},
+		{
+			name:           "expired link",
+			method:         http.MethodGet,
+			path:           "/expired",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusGone,
+		},
+		{
+			name:           "archived link",
+			method:         http.MethodGet,
+			path:           "/archived",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusGone,
+		},
+		{
+			name:           "root path",
+			method:         http.MethodGet,
+			path:           "/",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusNotFound,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodPost,
+			path:           "/active",
+			redirectStatus: http.StatusMovedPermanently,
+			wantStatus:     http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(store, tt.redirectStatus)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantLocation, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirect_RecordsClicks(t *testing.T) {
+	store := shortener.NewMemoryStore()
+	require.NoError(t, store.Save(shortener.Link{Slug: "abc", LongUrl: "https://www.google.com/"}))
+	handler := NewHandler(store, http.StatusMovedPermanently)
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abc", nil))
+	}
+
+	link, err := store.Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, 3, link.Clicks[time.Now().UTC().Format("2006-01-02")])
+}
@@ -20,31 +20,754 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type QRCodeFormat int32
+
+const (
+	QRCodeFormat_QR_CODE_FORMAT_PNG QRCodeFormat = 0
+	QRCodeFormat_QR_CODE_FORMAT_SVG QRCodeFormat = 1
+)
+
+// Enum value maps for QRCodeFormat.
+var (
+	QRCodeFormat_name = map[int32]string{
+		0: "QR_CODE_FORMAT_PNG",
+		1: "QR_CODE_FORMAT_SVG",
+	}
+	QRCodeFormat_value = map[string]int32{
+		"QR_CODE_FORMAT_PNG": 0,
+		"QR_CODE_FORMAT_SVG": 1,
+	}
+)
+
+func (x QRCodeFormat) Enum() *QRCodeFormat {
+	p := new(QRCodeFormat)
+	*p = x
+	return p
+}
+
+func (x QRCodeFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QRCodeFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_challenge_proto_enumTypes[0].Descriptor()
+}
+
+func (QRCodeFormat) Type() protoreflect.EnumType {
+	return &file_pkg_proto_challenge_proto_enumTypes[0]
+}
+
+func (x QRCodeFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QRCodeFormat.Descriptor instead.
+func (QRCodeFormat) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{0}
+}
+
+// Error correction level of QR code, higher levels survive more damage, but make code denser
+type QRCodeLevel int32
+
+const (
+	QRCodeLevel_QR_CODE_LEVEL_MEDIUM   QRCodeLevel = 0
+	QRCodeLevel_QR_CODE_LEVEL_LOW      QRCodeLevel = 1
+	QRCodeLevel_QR_CODE_LEVEL_QUARTILE QRCodeLevel = 2
+	QRCodeLevel_QR_CODE_LEVEL_HIGH     QRCodeLevel = 3
+)
+
+// Enum value maps for QRCodeLevel.
+var (
+	QRCodeLevel_name = map[int32]string{
+		0: "QR_CODE_LEVEL_MEDIUM",
+		1: "QR_CODE_LEVEL_LOW",
+		2: "QR_CODE_LEVEL_QUARTILE",
+		3: "QR_CODE_LEVEL_HIGH",
+	}
+	QRCodeLevel_value = map[string]int32{
+		"QR_CODE_LEVEL_MEDIUM":   0,
+		"QR_CODE_LEVEL_LOW":      1,
+		"QR_CODE_LEVEL_QUARTILE": 2,
+		"QR_CODE_LEVEL_HIGH":     3,
+	}
+)
+
+func (x QRCodeLevel) Enum() *QRCodeLevel {
+	p := new(QRCodeLevel)
+	*p = x
+	return p
+}
+
+func (x QRCodeLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QRCodeLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_proto_challenge_proto_enumTypes[1].Descriptor()
+}
+
+func (QRCodeLevel) Type() protoreflect.EnumType {
+	return &file_pkg_proto_challenge_proto_enumTypes[1]
+}
+
+func (x QRCodeLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QRCodeLevel.Descriptor instead.
+func (QRCodeLevel) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{1}
+}
+
 type Link struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Data string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Link) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+// Extended MakeShortLink request, data field is wire compatible with Link
+type ShortLinkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Optional custom back-half of the short link
+	Alias string `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	// Optional domain of the short link
+	Domain string `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	// Optional group of the short link(Bitly group guid)
+	GroupGuid string `protobuf:"bytes,4,opt,name=group_guid,json=groupGuid,proto3" json:"group_guid,omitempty"`
+	// Optional unix time when the link stops working
+	ExpiresAt int64 `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Optional lifetime of the link in seconds, alternative to expires_at
+	TtlSeconds int64 `protobuf:"varint,6,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+}
+
+func (x *ShortLinkRequest) Reset() {
+	*x = ShortLinkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortLinkRequest) ProtoMessage() {}
+
+func (x *ShortLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortLinkRequest.ProtoReflect.Descriptor instead.
+func (*ShortLinkRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ShortLinkRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *ShortLinkRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortLinkRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ShortLinkRequest) GetGroupGuid() string {
+	if x != nil {
+		return x.GroupGuid
+	}
+	return ""
+}
+
+func (x *ShortLinkRequest) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *ShortLinkRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ShortLinkBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Links []*ShortLinkRequest `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+}
+
+func (x *ShortLinkBatchRequest) Reset() {
+	*x = ShortLinkBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortLinkBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortLinkBatchRequest) ProtoMessage() {}
+
+func (x *ShortLinkBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortLinkBatchRequest.ProtoReflect.Descriptor instead.
+func (*ShortLinkBatchRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ShortLinkBatchRequest) GetLinks() []*ShortLinkRequest {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+// Result of shortening of single link in a batch
+type ShortLinkResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Long url from the request
+	Data string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Short link, empty if shortening failed
+	Link string `protobuf:"bytes,2,opt,name=link,proto3" json:"link,omitempty"`
+	// gRPC status code of shortening, 0 means success
+	Code  int32  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Name of the backend which produced short link, set when shortener reports it
+	Backend string `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+}
+
+func (x *ShortLinkResult) Reset() {
+	*x = ShortLinkResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortLinkResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortLinkResult) ProtoMessage() {}
+
+func (x *ShortLinkResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortLinkResult.ProtoReflect.Descriptor instead.
+func (*ShortLinkResult) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ShortLinkResult) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *ShortLinkResult) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *ShortLinkResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ShortLinkResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ShortLinkResult) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+type ShortLinkBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Results in the same order as links in the request
+	Results []*ShortLinkResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *ShortLinkBatchResponse) Reset() {
+	*x = ShortLinkBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortLinkBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortLinkBatchResponse) ProtoMessage() {}
+
+func (x *ShortLinkBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortLinkBatchResponse.ProtoReflect.Descriptor instead.
+func (*ShortLinkBatchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ShortLinkBatchResponse) GetResults() []*ShortLinkResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LinkStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Link string `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	// Size of the window in days, all time stats are returned if not positive
+	Days int64 `protobuf:"varint,2,opt,name=days,proto3" json:"days,omitempty"`
+	// Unix time of the end of the window, current time is used if not set
+	Until int64 `protobuf:"varint,3,opt,name=until,proto3" json:"until,omitempty"`
+}
+
+func (x *LinkStatsRequest) Reset() {
+	*x = LinkStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkStatsRequest) ProtoMessage() {}
+
+func (x *LinkStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkStatsRequest.ProtoReflect.Descriptor instead.
+func (*LinkStatsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LinkStatsRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *LinkStatsRequest) GetDays() int64 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *LinkStatsRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type DailyClicks struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Day in YYYY-MM-DD format
+	Date   string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Clicks int64  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+}
+
+func (x *DailyClicks) Reset() {
+	*x = DailyClicks{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DailyClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyClicks) ProtoMessage() {}
+
+func (x *DailyClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyClicks.ProtoReflect.Descriptor instead.
+func (*DailyClicks) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DailyClicks) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyClicks) GetClicks() int64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type LinkStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Link        string `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	TotalClicks int64  `protobuf:"varint,2,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	// Clicks per day in ascending order of date
+	Days []*DailyClicks `protobuf:"bytes,3,rep,name=days,proto3" json:"days,omitempty"`
+}
+
+func (x *LinkStats) Reset() {
+	*x = LinkStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkStats) ProtoMessage() {}
+
+func (x *LinkStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkStats.ProtoReflect.Descriptor instead.
+func (*LinkStats) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LinkStats) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *LinkStats) GetTotalClicks() int64 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+func (x *LinkStats) GetDays() []*DailyClicks {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+type LinkGroup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Guid string `protobuf:"bytes,1,opt,name=guid,proto3" json:"guid,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *LinkGroup) Reset() {
+	*x = LinkGroup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkGroup) ProtoMessage() {}
+
+func (x *LinkGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkGroup.ProtoReflect.Descriptor instead.
+func (*LinkGroup) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LinkGroup) GetGuid() string {
+	if x != nil {
+		return x.Guid
+	}
+	return ""
+}
+
+func (x *LinkGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Domains and groups which can be used in ShortLinkRequest
+type LinkDomains struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Domains []string     `protobuf:"bytes,1,rep,name=domains,proto3" json:"domains,omitempty"`
+	Groups  []*LinkGroup `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+}
+
+func (x *LinkDomains) Reset() {
+	*x = LinkDomains{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkDomains) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkDomains) ProtoMessage() {}
+
+func (x *LinkDomains) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkDomains.ProtoReflect.Descriptor instead.
+func (*LinkDomains) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LinkDomains) GetDomains() []string {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+func (x *LinkDomains) GetGroups() []*LinkGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// Changes of existing short link, fields which are not set are kept unchanged
+type UpdateLinkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Link string `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	// New title of the link
+	Title *string `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	// New tags of the link, replace all existing tags
+	Tags []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Removes all tags of the link, tags field is ignored
+	ClearTags bool `protobuf:"varint,4,opt,name=clear_tags,json=clearTags,proto3" json:"clear_tags,omitempty"`
+	// New long url the link points to(self-hosted links only)
+	LongUrl string `protobuf:"bytes,5,opt,name=long_url,json=longUrl,proto3" json:"long_url,omitempty"`
 }
 
-func (x *Link) Reset() {
-	*x = Link{}
+func (x *UpdateLinkRequest) Reset() {
+	*x = UpdateLinkRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_challenge_proto_msgTypes[0]
+		mi := &file_pkg_proto_challenge_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Link) String() string {
+func (x *UpdateLinkRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Link) ProtoMessage() {}
+func (*UpdateLinkRequest) ProtoMessage() {}
 
-func (x *Link) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_challenge_proto_msgTypes[0]
+func (x *UpdateLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,14 +778,178 @@ func (x *Link) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Link.ProtoReflect.Descriptor instead.
-func (*Link) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use UpdateLinkRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLinkRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Link) GetData() string {
+func (x *UpdateLinkRequest) GetLink() string {
 	if x != nil {
-		return x.Data
+		return x.Link
+	}
+	return ""
+}
+
+func (x *UpdateLinkRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateLinkRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateLinkRequest) GetClearTags() bool {
+	if x != nil {
+		return x.ClearTags
+	}
+	return false
+}
+
+func (x *UpdateLinkRequest) GetLongUrl() string {
+	if x != nil {
+		return x.LongUrl
+	}
+	return ""
+}
+
+type LinkQRCodeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Link   string       `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Format QRCodeFormat `protobuf:"varint,2,opt,name=format,proto3,enum=QRCodeFormat" json:"format,omitempty"`
+	// Width and height of the image in pixels, 256 is used if not set
+	Size  int32       `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Level QRCodeLevel `protobuf:"varint,4,opt,name=level,proto3,enum=QRCodeLevel" json:"level,omitempty"`
+}
+
+func (x *LinkQRCodeRequest) Reset() {
+	*x = LinkQRCodeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkQRCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkQRCodeRequest) ProtoMessage() {}
+
+func (x *LinkQRCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkQRCodeRequest.ProtoReflect.Descriptor instead.
+func (*LinkQRCodeRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LinkQRCodeRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *LinkQRCodeRequest) GetFormat() QRCodeFormat {
+	if x != nil {
+		return x.Format
+	}
+	return QRCodeFormat_QR_CODE_FORMAT_PNG
+}
+
+func (x *LinkQRCodeRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *LinkQRCodeRequest) GetLevel() QRCodeLevel {
+	if x != nil {
+		return x.Level
+	}
+	return QRCodeLevel_QR_CODE_LEVEL_MEDIUM
+}
+
+type LinkQRCode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Link  string `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Image []byte `protobuf:"bytes,2,opt,name=image,proto3" json:"image,omitempty"`
+	// MIME type of the image, image/png or image/svg+xml
+	ContentType string `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+}
+
+func (x *LinkQRCode) Reset() {
+	*x = LinkQRCode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_proto_challenge_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LinkQRCode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkQRCode) ProtoMessage() {}
+
+func (x *LinkQRCode) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_proto_challenge_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkQRCode.ProtoReflect.Descriptor instead.
+func (*LinkQRCode) Descriptor() ([]byte, []int) {
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LinkQRCode) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *LinkQRCode) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *LinkQRCode) GetContentType() string {
+	if x != nil {
+		return x.ContentType
 	}
 	return ""
 }
@@ -80,7 +967,7 @@ type Timer struct {
 func (x *Timer) Reset() {
 	*x = Timer{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_challenge_proto_msgTypes[1]
+		mi := &file_pkg_proto_challenge_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -93,7 +980,7 @@ func (x *Timer) String() string {
 func (*Timer) ProtoMessage() {}
 
 func (x *Timer) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_challenge_proto_msgTypes[1]
+	mi := &file_pkg_proto_challenge_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -106,7 +993,7 @@ func (x *Timer) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Timer.ProtoReflect.Descriptor instead.
 func (*Timer) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{1}
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Timer) GetName() string {
@@ -141,7 +1028,7 @@ type Placeholder struct {
 func (x *Placeholder) Reset() {
 	*x = Placeholder{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_proto_challenge_proto_msgTypes[2]
+		mi := &file_pkg_proto_challenge_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -154,7 +1041,7 @@ func (x *Placeholder) String() string {
 func (*Placeholder) ProtoMessage() {}
 
 func (x *Placeholder) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_proto_challenge_proto_msgTypes[2]
+	mi := &file_pkg_proto_challenge_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -167,7 +1054,7 @@ func (x *Placeholder) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Placeholder.ProtoReflect.Descriptor instead.
 func (*Placeholder) Descriptor() ([]byte, []int) {
-	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{2}
+	return file_pkg_proto_challenge_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Placeholder) GetData() string {
@@ -183,25 +1070,134 @@ var file_pkg_proto_challenge_proto_rawDesc = []byte{
 	0x0a, 0x19, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x68, 0x61, 0x6c,
 	0x6c, 0x65, 0x6e, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x1a, 0x0a, 0x04, 0x4c,
 	0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x53, 0x0a, 0x05, 0x54, 0x69, 0x6d, 0x65, 0x72,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1c,
-	0x0a, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x22, 0x21, 0x0a, 0x0b,
-	0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64,
-	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32,
-	0x7d, 0x0a, 0x10, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0d, 0x4d, 0x61, 0x6b, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x05, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x1a, 0x05, 0x2e, 0x4c, 0x69,
-	0x6e, 0x6b, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x72,
-	0x12, 0x06, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x1a, 0x06, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72,
-	0x30, 0x01, 0x12, 0x2a, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x64, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x12, 0x0c, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72,
-	0x1a, 0x0c, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x42, 0x27,
-	0x42, 0x0e, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f,
-	0x50, 0x01, 0x5a, 0x13, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x2f, 0x70, 0x6b,
-	0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xb3, 0x01, 0x0a, 0x10, 0x53, 0x68, 0x6f, 0x72,
+	0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x1d,
+	0x0a, 0x0a, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x67, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x47, 0x75, 0x69, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x40, 0x0a,
+	0x15, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e,
+	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x05, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x22,
+	0x7d, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x22, 0x44,
+	0x0a, 0x16, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x53, 0x68, 0x6f, 0x72,
+	0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x22, 0x50, 0x0a, 0x10, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x79, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x22, 0x39, 0x0a, 0x0b, 0x44, 0x61, 0x69, 0x6c, 0x79, 0x43,
+	0x6c, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x69,
+	0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x6c, 0x69, 0x63, 0x6b,
+	0x73, 0x22, 0x64, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69,
+	0x6e, 0x6b, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6c, 0x69, 0x63,
+	0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43,
+	0x6c, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x20, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x44, 0x61, 0x69, 0x6c, 0x79, 0x43, 0x6c, 0x69, 0x63, 0x6b,
+	0x73, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x22, 0x33, 0x0a, 0x09, 0x4c, 0x69, 0x6e, 0x6b, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x67, 0x75, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x4b, 0x0a, 0x0b,
+	0x4c, 0x69, 0x6e, 0x6b, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64,
+	0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x22, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x9a, 0x01, 0x0a, 0x11, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c,
+	0x69, 0x6e, 0x6b, 0x12, 0x19, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61,
+	0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x5f, 0x74, 0x61, 0x67, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6c, 0x65, 0x61, 0x72, 0x54, 0x61, 0x67,
+	0x73, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x6f, 0x6e, 0x67, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6c, 0x6f, 0x6e, 0x67, 0x55, 0x72, 0x6c, 0x42, 0x08, 0x0a, 0x06,
+	0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x86, 0x01, 0x0a, 0x11, 0x4c, 0x69, 0x6e, 0x6b, 0x51,
+	0x52, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b,
+	0x12, 0x25, 0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0d, 0x2e, 0x51, 0x52, 0x43, 0x6f, 0x64, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52,
+	0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x22, 0x0a, 0x05, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x51, 0x52, 0x43,
+	0x6f, 0x64, 0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22,
+	0x59, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x6b, 0x51, 0x52, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e,
+	0x6b, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x22, 0x53, 0x0a, 0x05, 0x54, 0x69,
+	0x6d, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x66, 0x72, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x22,
+	0x21, 0x0a, 0x0b, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x2a, 0x3e, 0x0a, 0x0c, 0x51, 0x52, 0x43, 0x6f, 0x64, 0x65, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x46, 0x4f,
+	0x52, 0x4d, 0x41, 0x54, 0x5f, 0x50, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x51, 0x52,
+	0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x53, 0x56, 0x47,
+	0x10, 0x01, 0x2a, 0x72, 0x0a, 0x0b, 0x51, 0x52, 0x43, 0x6f, 0x64, 0x65, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x18, 0x0a, 0x14, 0x51, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45, 0x56,
+	0x45, 0x4c, 0x5f, 0x4d, 0x45, 0x44, 0x49, 0x55, 0x4d, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x51,
+	0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f, 0x4c, 0x4f, 0x57,
+	0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x51, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45,
+	0x56, 0x45, 0x4c, 0x5f, 0x51, 0x55, 0x41, 0x52, 0x54, 0x49, 0x4c, 0x45, 0x10, 0x02, 0x12, 0x16,
+	0x0a, 0x12, 0x51, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x5f,
+	0x48, 0x49, 0x47, 0x48, 0x10, 0x03, 0x32, 0xe6, 0x03, 0x0a, 0x10, 0x43, 0x68, 0x61, 0x6c, 0x6c,
+	0x65, 0x6e, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x0d, 0x4d,
+	0x61, 0x6b, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x11, 0x2e, 0x53,
+	0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x05, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x41, 0x0a, 0x0e, 0x4d, 0x61, 0x6b, 0x65, 0x53, 0x68,
+	0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x16, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74,
+	0x4c, 0x69, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0f, 0x45, 0x78, 0x70,
+	0x61, 0x6e, 0x64, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x05, 0x2e, 0x4c,
+	0x69, 0x6e, 0x6b, 0x1a, 0x05, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x2d, 0x0a, 0x0f, 0x4c, 0x69,
+	0x73, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x0c, 0x2e,
+	0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x1a, 0x0c, 0x2e, 0x4c, 0x69,
+	0x6e, 0x6b, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x4c, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x11, 0x2e, 0x4c, 0x69, 0x6e, 0x6b,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0a, 0x2e, 0x4c,
+	0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c,
+	0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05, 0x2e, 0x4c, 0x69, 0x6e,
+	0x6b, 0x12, 0x1b, 0x0a, 0x0b, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x4c, 0x69, 0x6e, 0x6b,
+	0x12, 0x05, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x1a, 0x05, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x21,
+	0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x05, 0x2e, 0x4c,
+	0x69, 0x6e, 0x6b, 0x1a, 0x0c, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65,
+	0x72, 0x12, 0x30, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x51, 0x52, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x12, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x51, 0x52, 0x43, 0x6f, 0x64, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x51, 0x52, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x72, 0x12, 0x06, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x1a, 0x06, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x72, 0x30, 0x01, 0x12, 0x2a, 0x0a, 0x0c, 0x52, 0x65, 0x61, 0x64, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x0c, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65,
+	0x72, 0x1a, 0x0c, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x42,
+	0x27, 0x42, 0x0e, 0x43, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x50, 0x01, 0x5a, 0x13, 0x63, 0x68, 0x61, 0x6c, 0x6c, 0x65, 0x6e, 0x67, 0x65, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -216,24 +1212,61 @@ func file_pkg_proto_challenge_proto_rawDescGZIP() []byte {
 	return file_pkg_proto_challenge_proto_rawDescData
 }
 
-var file_pkg_proto_challenge_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pkg_proto_challenge_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pkg_proto_challenge_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_pkg_proto_challenge_proto_goTypes = []interface{}{
-	(*Link)(nil),        // 0: Link
-	(*Timer)(nil),       // 1: Timer
-	(*Placeholder)(nil), // 2: Placeholder
+	(QRCodeFormat)(0),              // 0: QRCodeFormat
+	(QRCodeLevel)(0),               // 1: QRCodeLevel
+	(*Link)(nil),                   // 2: Link
+	(*ShortLinkRequest)(nil),       // 3: ShortLinkRequest
+	(*ShortLinkBatchRequest)(nil),  // 4: ShortLinkBatchRequest
+	(*ShortLinkResult)(nil),        // 5: ShortLinkResult
+	(*ShortLinkBatchResponse)(nil), // 6: ShortLinkBatchResponse
+	(*LinkStatsRequest)(nil),       // 7: LinkStatsRequest
+	(*DailyClicks)(nil),            // 8: DailyClicks
+	(*LinkStats)(nil),              // 9: LinkStats
+	(*LinkGroup)(nil),              // 10: LinkGroup
+	(*LinkDomains)(nil),            // 11: LinkDomains
+	(*UpdateLinkRequest)(nil),      // 12: UpdateLinkRequest
+	(*LinkQRCodeRequest)(nil),      // 13: LinkQRCodeRequest
+	(*LinkQRCode)(nil),             // 14: LinkQRCode
+	(*Timer)(nil),                  // 15: Timer
+	(*Placeholder)(nil),            // 16: Placeholder
 }
 var file_pkg_proto_challenge_proto_depIdxs = []int32{
-	0, // 0: ChallengeService.MakeShortLink:input_type -> Link
-	1, // 1: ChallengeService.StartTimer:input_type -> Timer
-	2, // 2: ChallengeService.ReadMetadata:input_type -> Placeholder
-	0, // 3: ChallengeService.MakeShortLink:output_type -> Link
-	1, // 4: ChallengeService.StartTimer:output_type -> Timer
-	2, // 5: ChallengeService.ReadMetadata:output_type -> Placeholder
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3,  // 0: ShortLinkBatchRequest.links:type_name -> ShortLinkRequest
+	5,  // 1: ShortLinkBatchResponse.results:type_name -> ShortLinkResult
+	8,  // 2: LinkStats.days:type_name -> DailyClicks
+	10, // 3: LinkDomains.groups:type_name -> LinkGroup
+	0,  // 4: LinkQRCodeRequest.format:type_name -> QRCodeFormat
+	1,  // 5: LinkQRCodeRequest.level:type_name -> QRCodeLevel
+	3,  // 6: ChallengeService.MakeShortLink:input_type -> ShortLinkRequest
+	4,  // 7: ChallengeService.MakeShortLinks:input_type -> ShortLinkBatchRequest
+	2,  // 8: ChallengeService.ExpandShortLink:input_type -> Link
+	16, // 9: ChallengeService.ListLinkDomains:input_type -> Placeholder
+	7,  // 10: ChallengeService.GetLinkStats:input_type -> LinkStatsRequest
+	12, // 11: ChallengeService.UpdateLink:input_type -> UpdateLinkRequest
+	2,  // 12: ChallengeService.ArchiveLink:input_type -> Link
+	2,  // 13: ChallengeService.DeleteLink:input_type -> Link
+	13, // 14: ChallengeService.GetLinkQRCode:input_type -> LinkQRCodeRequest
+	15, // 15: ChallengeService.StartTimer:input_type -> Timer
+	16, // 16: ChallengeService.ReadMetadata:input_type -> Placeholder
+	2,  // 17: ChallengeService.MakeShortLink:output_type -> Link
+	6,  // 18: ChallengeService.MakeShortLinks:output_type -> ShortLinkBatchResponse
+	2,  // 19: ChallengeService.ExpandShortLink:output_type -> Link
+	11, // 20: ChallengeService.ListLinkDomains:output_type -> LinkDomains
+	9,  // 21: ChallengeService.GetLinkStats:output_type -> LinkStats
+	2,  // 22: ChallengeService.UpdateLink:output_type -> Link
+	2,  // 23: ChallengeService.ArchiveLink:output_type -> Link
+	16, // 24: ChallengeService.DeleteLink:output_type -> Placeholder
+	14, // 25: ChallengeService.GetLinkQRCode:output_type -> LinkQRCode
+	15, // 26: ChallengeService.StartTimer:output_type -> Timer
+	16, // 27: ChallengeService.ReadMetadata:output_type -> Placeholder
+	17, // [17:28] is the sub-list for method output_type
+	6,  // [6:17] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_pkg_proto_challenge_proto_init() }
@@ -255,7 +1288,7 @@ func file_pkg_proto_challenge_proto_init() {
 			}
 		}
 		file_pkg_proto_challenge_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Timer); i {
+			switch v := v.(*ShortLinkRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -267,6 +1300,150 @@ func file_pkg_proto_challenge_proto_init() {
 			}
 		}
 		file_pkg_proto_challenge_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShortLinkBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShortLinkResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShortLinkBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DailyClicks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkGroup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkDomains); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateLinkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkQRCodeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LinkQRCode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Timer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_proto_challenge_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Placeholder); i {
 			case 0:
 				return &v.state
@@ -279,18 +1456,20 @@ func file_pkg_proto_challenge_proto_init() {
 			}
 		}
 	}
+	file_pkg_proto_challenge_proto_msgTypes[10].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_proto_challenge_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      2,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_pkg_proto_challenge_proto_goTypes,
 		DependencyIndexes: file_pkg_proto_challenge_proto_depIdxs,
+		EnumInfos:         file_pkg_proto_challenge_proto_enumTypes,
 		MessageInfos:      file_pkg_proto_challenge_proto_msgTypes,
 	}.Build()
 	File_pkg_proto_challenge_proto = out.File
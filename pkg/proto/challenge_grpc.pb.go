@@ -22,7 +22,23 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ChallengeServiceClient interface {
-	MakeShortLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error)
+	MakeShortLink(ctx context.Context, in *ShortLinkRequest, opts ...grpc.CallOption) (*Link, error)
+	// Shortens many links at once, failure of a link doesn't fail whole batch
+	MakeShortLinks(ctx context.Context, in *ShortLinkBatchRequest, opts ...grpc.CallOption) (*ShortLinkBatchResponse, error)
+	// Resolves short link back to its long url
+	ExpandShortLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error)
+	// Lists domains and groups available to the shortener backend
+	ListLinkDomains(ctx context.Context, in *Placeholder, opts ...grpc.CallOption) (*LinkDomains, error)
+	// Returns clicks of short link over requested time window
+	GetLinkStats(ctx context.Context, in *LinkStatsRequest, opts ...grpc.CallOption) (*LinkStats, error)
+	// Changes title, tags or long url of existing short link
+	UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*Link, error)
+	// Archives short link, archived self-hosted links stop redirecting
+	ArchiveLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error)
+	// Deletes short link
+	DeleteLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Placeholder, error)
+	// Renders QR code image of short link
+	GetLinkQRCode(ctx context.Context, in *LinkQRCodeRequest, opts ...grpc.CallOption) (*LinkQRCode, error)
 	StartTimer(ctx context.Context, in *Timer, opts ...grpc.CallOption) (ChallengeService_StartTimerClient, error)
 	ReadMetadata(ctx context.Context, in *Placeholder, opts ...grpc.CallOption) (*Placeholder, error)
 }
@@ -35,7 +51,7 @@ func NewChallengeServiceClient(cc grpc.ClientConnInterface) ChallengeServiceClie
 	return &challengeServiceClient{cc}
 }
 
-func (c *challengeServiceClient) MakeShortLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error) {
+func (c *challengeServiceClient) MakeShortLink(ctx context.Context, in *ShortLinkRequest, opts ...grpc.CallOption) (*Link, error) {
 	out := new(Link)
 	err := c.cc.Invoke(ctx, "/ChallengeService/MakeShortLink", in, out, opts...)
 	if err != nil {
@@ -44,6 +60,78 @@ func (c *challengeServiceClient) MakeShortLink(ctx context.Context, in *Link, op
 	return out, nil
 }
 
+func (c *challengeServiceClient) MakeShortLinks(ctx context.Context, in *ShortLinkBatchRequest, opts ...grpc.CallOption) (*ShortLinkBatchResponse, error) {
+	out := new(ShortLinkBatchResponse)
+	err := c.cc.Invoke(ctx, "/ChallengeService/MakeShortLinks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) ExpandShortLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/ChallengeService/ExpandShortLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) ListLinkDomains(ctx context.Context, in *Placeholder, opts ...grpc.CallOption) (*LinkDomains, error) {
+	out := new(LinkDomains)
+	err := c.cc.Invoke(ctx, "/ChallengeService/ListLinkDomains", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) GetLinkStats(ctx context.Context, in *LinkStatsRequest, opts ...grpc.CallOption) (*LinkStats, error) {
+	out := new(LinkStats)
+	err := c.cc.Invoke(ctx, "/ChallengeService/GetLinkStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/ChallengeService/UpdateLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) ArchiveLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/ChallengeService/ArchiveLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) DeleteLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Placeholder, error) {
+	out := new(Placeholder)
+	err := c.cc.Invoke(ctx, "/ChallengeService/DeleteLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeServiceClient) GetLinkQRCode(ctx context.Context, in *LinkQRCodeRequest, opts ...grpc.CallOption) (*LinkQRCode, error) {
+	out := new(LinkQRCode)
+	err := c.cc.Invoke(ctx, "/ChallengeService/GetLinkQRCode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *challengeServiceClient) StartTimer(ctx context.Context, in *Timer, opts ...grpc.CallOption) (ChallengeService_StartTimerClient, error) {
 	stream, err := c.cc.NewStream(ctx, &ChallengeService_ServiceDesc.Streams[0], "/ChallengeService/StartTimer", opts...)
 	if err != nil {
@@ -89,7 +177,23 @@ func (c *challengeServiceClient) ReadMetadata(ctx context.Context, in *Placehold
 // All implementations must embed UnimplementedChallengeServiceServer
 // for forward compatibility
 type ChallengeServiceServer interface {
-	MakeShortLink(context.Context, *Link) (*Link, error)
+	MakeShortLink(context.Context, *ShortLinkRequest) (*Link, error)
+	// Shortens many links at once, failure of a link doesn't fail whole batch
+	MakeShortLinks(context.Context, *ShortLinkBatchRequest) (*ShortLinkBatchResponse, error)
+	// Resolves short link back to its long url
+	ExpandShortLink(context.Context, *Link) (*Link, error)
+	// Lists domains and groups available to the shortener backend
+	ListLinkDomains(context.Context, *Placeholder) (*LinkDomains, error)
+	// Returns clicks of short link over requested time window
+	GetLinkStats(context.Context, *LinkStatsRequest) (*LinkStats, error)
+	// Changes title, tags or long url of existing short link
+	UpdateLink(context.Context, *UpdateLinkRequest) (*Link, error)
+	// Archives short link, archived self-hosted links stop redirecting
+	ArchiveLink(context.Context, *Link) (*Link, error)
+	// Deletes short link
+	DeleteLink(context.Context, *Link) (*Placeholder, error)
+	// Renders QR code image of short link
+	GetLinkQRCode(context.Context, *LinkQRCodeRequest) (*LinkQRCode, error)
 	StartTimer(*Timer, ChallengeService_StartTimerServer) error
 	ReadMetadata(context.Context, *Placeholder) (*Placeholder, error)
 	mustEmbedUnimplementedChallengeServiceServer()
@@ -99,9 +203,33 @@ type ChallengeServiceServer interface {
 type UnimplementedChallengeServiceServer struct {
 }
 
-func (UnimplementedChallengeServiceServer) MakeShortLink(context.Context, *Link) (*Link, error) {
+func (UnimplementedChallengeServiceServer) MakeShortLink(context.Context, *ShortLinkRequest) (*Link, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MakeShortLink not implemented")
 }
+func (UnimplementedChallengeServiceServer) MakeShortLinks(context.Context, *ShortLinkBatchRequest) (*ShortLinkBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MakeShortLinks not implemented")
+}
+func (UnimplementedChallengeServiceServer) ExpandShortLink(context.Context, *Link) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExpandShortLink not implemented")
+}
+func (UnimplementedChallengeServiceServer) ListLinkDomains(context.Context, *Placeholder) (*LinkDomains, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLinkDomains not implemented")
+}
+func (UnimplementedChallengeServiceServer) GetLinkStats(context.Context, *LinkStatsRequest) (*LinkStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLinkStats not implemented")
+}
+func (UnimplementedChallengeServiceServer) UpdateLink(context.Context, *UpdateLinkRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLink not implemented")
+}
+func (UnimplementedChallengeServiceServer) ArchiveLink(context.Context, *Link) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveLink not implemented")
+}
+func (UnimplementedChallengeServiceServer) DeleteLink(context.Context, *Link) (*Placeholder, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLink not implemented")
+}
+func (UnimplementedChallengeServiceServer) GetLinkQRCode(context.Context, *LinkQRCodeRequest) (*LinkQRCode, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLinkQRCode not implemented")
+}
 func (UnimplementedChallengeServiceServer) StartTimer(*Timer, ChallengeService_StartTimerServer) error {
 	return status.Errorf(codes.Unimplemented, "method StartTimer not implemented")
 }
@@ -122,7 +250,7 @@ func RegisterChallengeServiceServer(s grpc.ServiceRegistrar, srv ChallengeServic
 }
 
 func _ChallengeService_MakeShortLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Link)
+	in := new(ShortLinkRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -134,7 +262,151 @@ func _ChallengeService_MakeShortLink_Handler(srv interface{}, ctx context.Contex
 		FullMethod: "/ChallengeService/MakeShortLink",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ChallengeServiceServer).MakeShortLink(ctx, req.(*Link))
+		return srv.(ChallengeServiceServer).MakeShortLink(ctx, req.(*ShortLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_MakeShortLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortLinkBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).MakeShortLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/MakeShortLinks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).MakeShortLinks(ctx, req.(*ShortLinkBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_ExpandShortLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Link)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).ExpandShortLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/ExpandShortLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).ExpandShortLink(ctx, req.(*Link))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_ListLinkDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Placeholder)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).ListLinkDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/ListLinkDomains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).ListLinkDomains(ctx, req.(*Placeholder))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_GetLinkStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).GetLinkStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/GetLinkStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).GetLinkStats(ctx, req.(*LinkStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_UpdateLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).UpdateLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/UpdateLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).UpdateLink(ctx, req.(*UpdateLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_ArchiveLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Link)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).ArchiveLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/ArchiveLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).ArchiveLink(ctx, req.(*Link))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_DeleteLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Link)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).DeleteLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/DeleteLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).DeleteLink(ctx, req.(*Link))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChallengeService_GetLinkQRCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkQRCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServiceServer).GetLinkQRCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ChallengeService/GetLinkQRCode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServiceServer).GetLinkQRCode(ctx, req.(*LinkQRCodeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -189,6 +461,38 @@ var ChallengeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MakeShortLink",
 			Handler:    _ChallengeService_MakeShortLink_Handler,
 		},
+		{
+			MethodName: "MakeShortLinks",
+			Handler:    _ChallengeService_MakeShortLinks_Handler,
+		},
+		{
+			MethodName: "ExpandShortLink",
+			Handler:    _ChallengeService_ExpandShortLink_Handler,
+		},
+		{
+			MethodName: "ListLinkDomains",
+			Handler:    _ChallengeService_ListLinkDomains_Handler,
+		},
+		{
+			MethodName: "GetLinkStats",
+			Handler:    _ChallengeService_GetLinkStats_Handler,
+		},
+		{
+			MethodName: "UpdateLink",
+			Handler:    _ChallengeService_UpdateLink_Handler,
+		},
+		{
+			MethodName: "ArchiveLink",
+			Handler:    _ChallengeService_ArchiveLink_Handler,
+		},
+		{
+			MethodName: "DeleteLink",
+			Handler:    _ChallengeService_DeleteLink_Handler,
+		},
+		{
+			MethodName: "GetLinkQRCode",
+			Handler:    _ChallengeService_GetLinkQRCode_Handler,
+		},
 		{
 			MethodName: "ReadMetadata",
 			Handler:    _ChallengeService_ReadMetadata_Handler,
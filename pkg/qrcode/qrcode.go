@@ -0,0 +1,118 @@
+// Package qrcode renders QR codes of links locally, so it works without any external service
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	qr "github.com/skip2/go-qrcode"
+	"strings"
+)
+
+var (
+	ErrInternal      = errors.New("internal qrcode error")
+	ErrInvalidFormat = errors.New("invalid image format")
+	ErrInvalidSize   = errors.New("invalid image size")
+	ErrInvalidLevel  = errors.New("invalid error correction level")
+	ErrInvalidData   = errors.New("invalid qr code data")
+)
+
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+// Level is error correction level of QR code, higher levels survive more damage, but make code denser
+type Level string
+
+const (
+	LevelLow      Level = "L"
+	LevelMedium   Level = "M"
+	LevelQuartile Level = "Q"
+	LevelHigh     Level = "H"
+)
+
+const (
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 4096
+)
+
+var levels = map[Level]qr.RecoveryLevel{
+	LevelLow:      qr.Low,
+	LevelMedium:   qr.Medium,
+	LevelQuartile: qr.High,
+	LevelHigh:     qr.Highest,
+}
+
+// Options of rendered image, zero values are replaced with png, DefaultSize and medium level
+type Options struct {
+	Format Format
+	// Size is width and height of the image in pixels
+	Size  int
+	Level Level
+}
+
+// Encode renders QR code with given data and returns image bytes with their content type
+//
+// ErrInvalidData returned when data is empty or too long to fit QR code
+func Encode(data string, opts Options) ([]byte, string, error) {
+	if data == "" {
+		return nil, "", fmt.Errorf("%w: empty data", ErrInvalidData)
+	}
+
+	size := opts.Size
+	if size == 0 {
+		size = DefaultSize
+	}
+	if size < MinSize || size > MaxSize {
+		return nil, "", fmt.Errorf("%w: must be between %d and %d, got %d", ErrInvalidSize, MinSize, MaxSize, size)
+	}
+
+	level := opts.Level
+	if level == "" {
+		level = LevelMedium
+	}
+	recovery, ok := levels[Level(strings.ToUpper(string(level)))]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidLevel, level)
+	}
+
+	code, err := qr.New(data, recovery)
+	if err != nil {
+		// Library fails only when data doesn't fit the largest QR code version
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	switch opts.Format {
+	case FormatPNG, "":
+		png, err := code.PNG(size)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+		return png, "image/png", nil
+	case FormatSVG:
+		return svg(code.Bitmap(), size), "image/svg+xml", nil
+	default:
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidFormat, opts.Format)
+	}
+}
+
+// svg draws every dark module as unit square of single path, viewBox scales modules to requested size
+func svg(bitmap [][]bool, size int) []byte {
+	var path strings.Builder
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	return []byte(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`,
+		size, size, len(bitmap), len(bitmap), path.String(),
+	))
+}
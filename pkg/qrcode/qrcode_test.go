@@ -0,0 +1,91 @@
+package qrcode
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncode_TestCases(t *testing.T) {
+	tc := []struct {
+		name            string
+		data            string
+		opts            Options
+		wantContentType string
+		wantSize        int
+		wantErr         error
+	}{
+		{
+			name:            "defaults",
+			data:            "https://bit.ly/abc",
+			wantContentType: "image/png",
+			wantSize:        DefaultSize,
+		},
+		{
+			name:            "png with size and level",
+			data:            "https://bit.ly/abc",
+			opts:            Options{Format: FormatPNG, Size: 512, Level: LevelHigh},
+			wantContentType: "image/png",
+			wantSize:        512,
+		},
+		{
+			name:            "svg, lowercase level",
+			data:            "https://bit.ly/abc",
+			opts:            Options{Format: FormatSVG, Size: 128, Level: "q"},
+			wantContentType: "image/svg+xml",
+			wantSize:        128,
+		},
+		{
+			name:    "empty data",
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "too long data",
+			data:    strings.Repeat("a", 8000),
+			wantErr: ErrInvalidData,
+		},
+		{
+			name:    "too small",
+			data:    "https://bit.ly/abc",
+			opts:    Options{Size: 10},
+			wantErr: ErrInvalidSize,
+		},
+		{
+			name:    "unknown format",
+			data:    "https://bit.ly/abc",
+			opts:    Options{Format: "gif"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "unknown level",
+			data:    "https://bit.ly/abc",
+			opts:    Options{Level: "X"},
+			wantErr: ErrInvalidLevel,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			image, contentType, err := Encode(tt.data, tt.opts)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContentType, contentType)
+
+			if contentType == "image/png" {
+				img, err := png.Decode(bytes.NewReader(image))
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantSize, img.Bounds().Dx())
+				assert.Equal(t, tt.wantSize, img.Bounds().Dy())
+				return
+			}
+			assert.Contains(t, string(image), `width="128" height="128"`)
+			assert.True(t, strings.HasPrefix(string(image), "<svg"))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package shortener
+
+import (
+	"context"
+	"sync"
+)
+
+type backendKey struct{}
+
+// backendRecorder keeps name of the backend which produced link of the request
+type backendRecorder struct {
+	mu   sync.Mutex
+	name string
+}
+
+// WithBackendRecorder returns ctx in which shorteners record name of the backend that produced link,
+// returned function reports recorded name, empty if no shortener recorded it
+func WithBackendRecorder(ctx context.Context) (context.Context, func() string) {
+	r := &backendRecorder{}
+	return context.WithValue(ctx, backendKey{}, r), func() string {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.name
+	}
+}
+
+// RecordBackend saves name of the backend that produced link to recorder of ctx, if there is one
+//
+// Decorators record their name only when they don't call underlying backend,
+// so the name of the last shortener that actually produced link is kept
+func RecordBackend(ctx context.Context, name string) {
+	r, ok := ctx.Value(backendKey{}).(*backendRecorder)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.name = name
+}
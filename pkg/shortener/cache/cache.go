@@ -0,0 +1,233 @@
+// Package cache provides deduplicating cache in front of url shortener
+// Repeated requests for the same(normalized) long url return the same short link without upstream call
+package cache
+
+import (
+	"challenge/pkg/shortener"
+	"challenge/pkg/urlnorm"
+	"container/list"
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// backendName is recorded as producer of links returned from cache
+const backendName = "cache"
+
+type Entry struct {
+	Link      string    `json:"link"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Cache is UrlShortener decorator which remembers created links
+//
+// Entries are evicted after ttl(if positive) and least recently used entries
+// are evicted when size exceeds maxSize(if positive)
+type Cache struct {
+	next    shortener.UrlShortener
+	ttl     time.Duration
+	maxSize int
+	store   Store
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	inflight map[string]*call
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type item struct {
+	key   string
+	entry Entry
+}
+
+// call is upstream request shared by concurrent misses of the same key, done is closed when it's finished
+type call struct {
+	done  chan struct{}
+	entry Entry
+	err   error
+}
+
+// Option configures Cache on creation
+type Option func(*Cache)
+
+// WithStore adds persistent layer, which is checked on memory miss and filled on every upstream call
+func WithStore(store Store) Option {
+	return func(c *Cache) {
+		c.store = store
+	}
+}
+
+func NewCache(next shortener.UrlShortener, ttl time.Duration, maxSize int, opts ...Option) *Cache {
+	c := &Cache{
+		next:     next,
+		ttl:      ttl,
+		maxSize:  maxSize,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		inflight: make(map[string]*call),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// CreateShortLink returns cached short link of the given long url or creates it with underlying shortener
+//
+// Urls which can't be normalized are passed to underlying shortener without caching
+func (c *Cache) CreateShortLink(longUrl string) (string, error) {
+	return c.CreateShortLinkContext(context.Background(), longUrl)
+}
+
+// CreateShortLinkContext is CreateShortLink which passes ctx to underlying shortener
+//
+// Concurrent misses of the same url share upstream call made with ctx of the first of them,
+// others stop waiting for it when their own ctx is done
+func (c *Cache) CreateShortLinkContext(ctx context.Context, longUrl string) (string, error) {
+	key, err := urlnorm.Normalize(longUrl)
+	if err != nil {
+		return shortener.CreateShortLinkContext(ctx, c.next, longUrl)
+	}
+
+	c.mu.Lock()
+	if link, ok := c.get(key); ok {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		shortener.RecordBackend(ctx, backendName)
+		return link, nil
+	}
+	if cl, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		// Failed shared call isn't served by cache, it is already counted as miss by its caller
+		if cl.err == nil {
+			c.hits.Add(1)
+			shortener.RecordBackend(ctx, backendName)
+		}
+		return cl.entry.Link, cl.err
+	}
+	cl := &call{done: make(chan struct{})}
+	c.inflight[key] = cl
+	c.mu.Unlock()
+
+	cl.entry, cl.err = c.load(ctx, key, longUrl)
+	close(cl.done)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if cl.err == nil {
+		c.set(key, cl.entry)
+	}
+	c.mu.Unlock()
+
+	return cl.entry.Link, cl.err
+}
+
+// Invalidate forgets given short link, so it's created again on next request for its long url
+//
+// Must be called when link is deleted or points to another long url
+func (c *Cache) Invalidate(link string) {
+	c.mu.Lock()
+	for key, el := range c.entries {
+		if el.Value.(*item).entry.Link == link {
+			c.order.Remove(el)
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.Forget(link); err != nil {
+			log.Printf("failed to forget link in cache store. err: %v\n", err)
+		}
+	}
+}
+
+// Stats returns amount of cache hits and misses since creation
+func (c *Cache) Stats() (hits int64, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Unwrap returns underlying shortener
+func (c *Cache) Unwrap() shortener.UrlShortener {
+	return c.next
+}
+
+// load gets link from persistent store or creates it with underlying shortener
+func (c *Cache) load(ctx context.Context, key string, longUrl string) (Entry, error) {
+	if c.store != nil {
+		entry, ok, err := c.store.Load(key)
+		if err != nil {
+			log.Printf("failed to load link from cache store. err: %v\n", err)
+		}
+		if ok && !c.expired(entry) {
+			c.hits.Add(1)
+			shortener.RecordBackend(ctx, backendName)
+			return entry, nil
+		}
+	}
+
+	c.misses.Add(1)
+	link, err := shortener.CreateShortLinkContext(ctx, c.next, longUrl)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Link: link, CreatedAt: time.Now()}
+	if c.store != nil {
+		// Failed persisting must not fail created link
+		if err := c.store.Save(key, entry); err != nil {
+			log.Printf("failed to save link to cache store. err: %v\n", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// get returns not expired link from memory, c.mu must be held
+func (c *Cache) get(key string) (string, bool) {
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	it := el.Value.(*item)
+	if c.expired(it.entry) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+
+	return it.entry.Link, true
+}
+
+// set puts link to memory and evicts least recently used links over the size limit, c.mu must be held
+func (c *Cache) set(key string, entry Entry) {
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*item).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&item{key: key, entry: entry})
+	for c.maxSize > 0 && c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*item).key)
+	}
+}
+
+func (c *Cache) expired(entry Entry) bool {
+	return c.ttl > 0 && time.Since(entry.CreatedAt) >= c.ttl
+}
@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingShortener returns new link on every call
+type countingShortener struct {
+	calls atomic.Int64
+	err   error
+}
+
+func (s *countingShortener) CreateShortLink(_ string) (string, error) {
+	n := s.calls.Add(1)
+	if s.err != nil {
+		return "", s.err
+	}
+	// Slow upstream makes concurrent misses overlap
+	time.Sleep(time.Millisecond)
+	return fmt.Sprintf("https://sho.rt/%d", n), nil
+}
+
+func TestCache_Deduplication(t *testing.T) {
+	next := &countingShortener{}
+	c := NewCache(next, time.Hour, 0)
+
+	first, err := c.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	// The same url written differently must hit the cache
+	for _, url := range []string{"https://www.google.com/", "HTTPS://WWW.GOOGLE.COM", "https://www.google.com:443/"} {
+		got, err := c.CreateShortLink(url)
+		require.NoError(t, err)
+		assert.Equal(t, first, got)
+	}
+
+	// Urls which lead to different places must not share short link
+	for _, url := range []string{"https://github.com/", "https://www.google.com/#top", "https://www.google.com/?a=1;b=2"} {
+		other, err := c.CreateShortLink(url)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, other, url)
+	}
+
+	assert.EqualValues(t, 4, next.calls.Load())
+	hits, misses := c.Stats()
+	assert.EqualValues(t, 3, hits)
+	assert.EqualValues(t, 4, misses)
+}
+
+func TestCache_TTL(t *testing.T) {
+	next := &countingShortener{}
+	c := NewCache(next, 10*time.Millisecond, 0)
+
+	first, err := c.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	second, err := c.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.EqualValues(t, 2, next.calls.Load())
+}
+
+func TestCache_MaxSize(t *testing.T) {
+	next := &countingShortener{}
+	c := NewCache(next, 0, 2)
+
+	for _, url := range []string{"https://a.com/", "https://b.com/", "https://a.com/", "https://c.com/"} {
+		_, err := c.CreateShortLink(url)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 3, next.calls.Load())
+
+	// b.com is least recently used, so it was evicted
+	_, err := c.CreateShortLink("https://b.com/")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, next.calls.Load())
+
+	_, err = c.CreateShortLink("https://c.com/")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, next.calls.Load())
+}
+
+func TestCache_ErrorsNotCached(t *testing.T) {
+	next := &countingShortener{err: errors.New("something goes wrong")}
+	c := NewCache(next, time.Hour, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.CreateShortLink("https://www.google.com/")
+		assert.Error(t, err)
+	}
+	assert.EqualValues(t, 2, next.calls.Load())
+}
+
+func TestCache_SharedErrorNotHit(t *testing.T) {
+	next := &blockingShortener{release: make(chan struct{}), err: errors.New("something goes wrong")}
+	c := NewCache(next, time.Hour, 0)
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.CreateShortLinkContext(context.Background(), "https://www.google.com/")
+		errs <- err
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.inflight) == 1
+	}, time.Second, time.Millisecond)
+	go func() {
+		_, err := c.CreateShortLinkContext(context.Background(), "https://www.google.com/")
+		errs <- err
+	}()
+	// Let the second call wait for the shared one
+	time.Sleep(10 * time.Millisecond)
+
+	close(next.release)
+	for range 2 {
+		assert.ErrorIs(t, <-errs, next.err)
+	}
+	hits, misses := c.Stats()
+	assert.EqualValues(t, 0, hits)
+	assert.EqualValues(t, 1, misses)
+}
+
+func TestCache_ConcurrentMisses(t *testing.T) {
+	next := &countingShortener{}
+	c := NewCache(next, time.Hour, 0)
+
+	links := make([]string, 50)
+	wg := sync.WaitGroup{}
+	for i := range links {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			link, err := c.CreateShortLink("https://www.google.com/")
+			assert.NoError(t, err)
+			links[i] = link
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, next.calls.Load())
+	for _, link := range links {
+		assert.Equal(t, links[0], link)
+	}
+}
+
+func TestCache_PersistentStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	store, err := NewFileStore(path, time.Hour, 0)
+	require.NoError(t, err)
+
+	next := &countingShortener{}
+	first, err := NewCache(next, time.Hour, 0, WithStore(store)).CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	// New cache with reopened store simulates restart
+	reopened, err := NewFileStore(path, time.Hour, 0)
+	require.NoError(t, err)
+	second, err := NewCache(next, time.Hour, 0, WithStore(reopened)).CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.EqualValues(t, 1, next.calls.Load())
+}
+
+func TestFileStore_Bounds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	now := time.Now()
+	store, err := NewFileStore(path, time.Hour, 2)
+	require.NoError(t, err)
+
+	// Expired entry is dropped on save
+	require.NoError(t, store.Save("expired", Entry{Link: "https://sho.rt/1", CreatedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, store.Save("old", Entry{Link: "https://sho.rt/2", CreatedAt: now.Add(-2 * time.Minute)}))
+	_, ok, err := store.Load("expired")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// The oldest entry is dropped over the size limit
+	require.NoError(t, store.Save("new", Entry{Link: "https://sho.rt/3", CreatedAt: now.Add(-time.Minute)}))
+	require.NoError(t, store.Save("newest", Entry{Link: "https://sho.rt/4", CreatedAt: now}))
+	reopened, err := NewFileStore(path, time.Hour, 2)
+	require.NoError(t, err)
+	for key, want := range map[string]bool{"old": false, "new": true, "newest": true} {
+		_, ok, err := reopened.Load(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, key)
+	}
+
+	// Entries expired while store was closed are dropped on load
+	reopened, err = NewFileStore(path, time.Nanosecond, 0)
+	require.NoError(t, err)
+	_, ok, err = reopened.Load("newest")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	store, err := NewFileStore(path, time.Hour, 0)
+	require.NoError(t, err)
+
+	next := &countingShortener{}
+	c := NewCache(next, time.Hour, 0, WithStore(store))
+	first, err := c.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+
+	c.Invalidate(first)
+
+	second, err := c.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.EqualValues(t, 2, next.calls.Load())
+}
+
+// blockingShortener waits for release or cancellation of ctx, then it returns err if it is set
+type blockingShortener struct {
+	release chan struct{}
+	err     error
+}
+
+func (s *blockingShortener) CreateShortLink(url string) (string, error) {
+	return s.CreateShortLinkContext(context.Background(), url)
+}
+
+func (s *blockingShortener) CreateShortLinkContext(ctx context.Context, _ string) (string, error) {
+	select {
+	case <-s.release:
+		if s.err != nil {
+			return "", s.err
+		}
+		return "https://sho.rt/abc", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestCache_ContextCancellation(t *testing.T) {
+	next := &blockingShortener{release: make(chan struct{})}
+	c := NewCache(next, time.Hour, 0)
+
+	// Canceled context reaches upstream call
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.CreateShortLinkContext(canceled, "https://www.google.com/")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Waiter of shared call stops on its own context, while the call itself completes
+	leader := make(chan string)
+	go func() {
+		link, _ := c.CreateShortLinkContext(context.Background(), "https://www.google.com/")
+		leader <- link
+	}()
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.inflight) == 1
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.CreateShortLinkContext(ctx, "https://www.google.com/")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(next.release)
+	assert.Equal(t, "https://sho.rt/abc", <-leader)
+}
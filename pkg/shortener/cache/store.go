@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store is a persistent layer of the cache
+//
+// Load returns false if there is no entry for given key
+// Forget removes all entries of given short link
+type Store interface {
+	Load(key string) (Entry, bool, error)
+	Save(key string, entry Entry) error
+	Forget(link string) error
+}
+
+// FileStore keeps cache entries in json file, so cached links survive restarts
+//
+// Entries are dropped after ttl(if positive) and the oldest entries are dropped when size exceeds maxSize(if positive),
+// the same way as in Cache, so the file doesn't grow with every shortened url
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	maxSize int
+	entries map[string]Entry
+}
+
+// NewFileStore creates store bound to file in given path, expired entries of existing file are dropped
+//
+// If file not exists, it will be created on first save
+func NewFileStore(path string, ttl time.Duration, maxSize int) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]Entry),
+	}
+
+	bts, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	if len(bts) != 0 {
+		if err := json.Unmarshal(bts, &s.entries); err != nil {
+			return nil, fmt.Errorf("failed to decode cache file: %w", err)
+		}
+	}
+	// Dropped entries are removed from the file on next save
+	s.prune()
+
+	return s, nil
+}
+
+func (s *FileStore) Load(key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+func (s *FileStore) Save(key string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry
+	s.prune()
+
+	return s.flush()
+}
+
+func (s *FileStore) Forget(link string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.DeleteFunc(s.entries, func(_ string, entry Entry) bool {
+		return entry.Link == link
+	})
+
+	return s.flush()
+}
+
+// prune drops expired entries and the oldest entries over the size limit, s.mu must be held
+func (s *FileStore) prune() {
+	if s.ttl > 0 {
+		maps.DeleteFunc(s.entries, func(_ string, entry Entry) bool {
+			return time.Since(entry.CreatedAt) >= s.ttl
+		})
+	}
+	if s.maxSize <= 0 || len(s.entries) <= s.maxSize {
+		return
+	}
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return s.entries[a].CreatedAt.Compare(s.entries[b].CreatedAt)
+	})
+	for _, key := range keys[:len(keys)-s.maxSize] {
+		delete(s.entries, key)
+	}
+}
+
+// flush writes all entries to cache file, s.mu must be held
+func (s *FileStore) flush() error {
+	bts, err := json.Marshal(s.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	// Write to temporary file first, so cache file never stays partially written
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, bts, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package shortener
+
+import (
+	"context"
+	"time"
+)
+
+// Context variants make Shortener interchangeable with API backends. Links are stored locally,
+// so operations are short and ctx is checked only before they start
+
+func (s *Shortener) CreateShortLinkContext(ctx context.Context, longUrl string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.CreateShortLink(longUrl)
+}
+
+func (s *Shortener) CreateCustomShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, group string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.CreateCustomShortLink(longUrl, alias, domain, group)
+}
+
+func (s *Shortener) CreateExpiringShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, group string, expiresAt time.Time) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.CreateExpiringShortLink(longUrl, alias, domain, group, expiresAt)
+}
+
+func (s *Shortener) ListDomainsContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.ListDomains()
+}
+
+func (s *Shortener) ExpandShortLinkContext(ctx context.Context, shortUrl string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return s.ExpandShortLink(shortUrl)
+}
+
+func (s *Shortener) UpdateLinkContext(ctx context.Context, shortUrl string, title *string, tags []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.UpdateLink(shortUrl, title, tags)
+}
+
+func (s *Shortener) RetargetLinkContext(ctx context.Context, shortUrl string, longUrl string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.RetargetLink(shortUrl, longUrl)
+}
+
+func (s *Shortener) EditLinkContext(ctx context.Context, shortUrl string, longUrl string, title *string, tags []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.EditLink(shortUrl, longUrl, title, tags)
+}
+
+func (s *Shortener) ArchiveLinkContext(ctx context.Context, shortUrl string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.ArchiveLink(shortUrl)
+}
+
+func (s *Shortener) DeleteLinkContext(ctx context.Context, shortUrl string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.DeleteLink(shortUrl)
+}
+
+func (s *Shortener) GetLinkStatsContext(ctx context.Context, shortUrl string, days int, until time.Time) (int, map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+	return s.GetLinkStats(shortUrl, days, until)
+}
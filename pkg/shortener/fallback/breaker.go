@@ -0,0 +1,74 @@
+package fallback
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is circuit breaker of single backend
+//
+// It opens after threshold consecutive failures and rejects calls for cooldown.
+// After cooldown single trial call is allowed(half-open state): its success closes breaker,
+// its failure opens it for another cooldown
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow reports whether backend can be called now
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+
+	b.trial = true
+	return true
+}
+
+// success closes breaker
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// failure counts failed call and opens breaker when threshold is reached
+func (b *breaker) failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// release ends trial call which result says nothing about backend health
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// open reports whether breaker currently rejects calls
+func (b *breaker) open(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.threshold && now.Before(b.openUntil)
+}
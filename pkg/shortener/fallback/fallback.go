@@ -0,0 +1,216 @@
+// Package fallback provides shortener which passes request to the next backend when previous one fails
+// Failing backends are skipped for a cool-down period by per-backend circuit breaker
+package fallback
+
+import (
+	"challenge/pkg/api/bilty"
+	"challenge/pkg/shortener"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnavailable = errors.New("all shortener backends are unavailable")
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	ErrUnsupported = errors.New("capability is not supported by shortener backend")
+)
+
+const (
+	defaultThreshold = 5
+	defaultCooldown  = 30 * time.Second
+)
+
+// Backend is named shortener in the chain, name is reported as producer of links
+type Backend struct {
+	Name      string
+	Shortener shortener.UrlShortener
+}
+
+type backend struct {
+	Backend
+	breaker *breaker
+}
+
+// Chain is UrlShortener which tries backends in order until one of them creates link
+//
+// Custom and expiring links are created the same way by backends which support them, while existing links
+// are managed(expanded, counted, updated, archived and deleted) by backend which created them.
+//
+// Only backend failures(see BackendFailure) pass request to the next backend and count by breaker,
+// errors caused by request itself are returned as is
+type Chain struct {
+	backends  []*backend
+	threshold int
+	cooldown  time.Duration
+	failover  func(err error) bool
+	now       func() time.Time
+}
+
+// Option configures Chain on creation
+type Option func(*Chain)
+
+// WithBreaker sets amount of consecutive failures which open backend breaker and duration it stays open
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Chain) {
+		if threshold > 0 {
+			c.threshold = threshold
+		}
+		if cooldown > 0 {
+			c.cooldown = cooldown
+		}
+	}
+}
+
+// WithFailover replaces BackendFailure, which decides whether error is failure of backend
+func WithFailover(failover func(err error) bool) Option {
+	return func(c *Chain) {
+		c.failover = failover
+	}
+}
+
+func NewChain(backends []Backend, opts ...Option) *Chain {
+	c := &Chain{
+		threshold: defaultThreshold,
+		cooldown:  defaultCooldown,
+		failover:  BackendFailure,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, b := range backends {
+		c.backends = append(c.backends, &backend{
+			Backend: b,
+			breaker: &breaker{threshold: c.threshold, cooldown: c.cooldown},
+		})
+	}
+
+	return c
+}
+
+// CreateShortLink creates link with the first available backend
+func (c *Chain) CreateShortLink(longUrl string) (string, error) {
+	return c.CreateShortLinkContext(context.Background(), longUrl)
+}
+
+// CreateShortLinkContext is CreateShortLink which passes ctx to backends
+// Name of the backend which created link is recorded to ctx, see shortener.WithBackendRecorder
+//
+// ErrUnavailable returned when every backend failed or was skipped by its breaker,
+// it wraps errors of all backends
+func (c *Chain) CreateShortLinkContext(ctx context.Context, longUrl string) (string, error) {
+	return create(ctx, c, func(s shortener.UrlShortener) (string, error) {
+		return shortener.CreateShortLinkContext(ctx, s, longUrl)
+	})
+}
+
+// create tries backends which implement T in order until one of them creates link
+//
+// Backend which can't serve options of requested link(see unservable) is skipped the same way
+// as backend which doesn't implement T, but it isn't counted as failure by breaker.
+// ErrUnavailable returned when every backend failed or was skipped by its breaker,
+// ErrUnsupported returned when no backend implements T, otherwise errors of backends which can't create link
+// are returned as is
+func create[T any](ctx context.Context, c *Chain, create func(s T) (string, error)) (string, error) {
+	var errs []error
+	supported := false
+	failed := false
+	for _, b := range c.backends {
+		s, ok := shortener.As[T](b.Shortener)
+		if !ok {
+			continue
+		}
+		supported = true
+		if !b.breaker.allow(c.now()) {
+			failed = true
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, ErrCircuitOpen))
+			continue
+		}
+
+		link, err := create(s)
+		if err == nil {
+			b.breaker.success()
+			shortener.RecordBackend(ctx, b.Name)
+			return link, nil
+		}
+		if unservable(err) && ctx.Err() == nil {
+			log.Printf("shortener backend %s can't create link, trying next one. err: %v\n", b.Name, err)
+			b.breaker.release()
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+			continue
+		}
+		if !c.failover(err) || ctx.Err() != nil {
+			b.breaker.release()
+			return "", err
+		}
+
+		log.Printf("shortener backend %s failed, trying next one. err: %v\n", b.Name, err)
+		b.breaker.failure(c.now())
+		failed = true
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+	}
+	if !supported {
+		return "", fmt.Errorf("%w: %v", ErrUnsupported, "no backend supports requested link options")
+	}
+	if !failed {
+		return "", errors.Join(errs...)
+	}
+
+	return "", fmt.Errorf("%w: %w", ErrUnavailable, errors.Join(errs...))
+}
+
+// unservable reports whether backend can't serve options of requested link, e.g. Bitly plan doesn't support
+// expiration or self-hosted shortener is asked for group or other domain, while the next backend may serve them
+func unservable(err error) bool {
+	return errors.Is(err, bilty.ErrUpgradeRequired) || errors.Is(err, shortener.ErrInvalidGroup) ||
+		errors.Is(err, shortener.ErrInvalidDomain)
+}
+
+// Available reports whether breaker of each backend lets requests through, by backend name
+func (c *Chain) Available() map[string]bool {
+	now := c.now()
+	available := make(map[string]bool, len(c.backends))
+	for _, b := range c.backends {
+		available[b.Name] = !b.breaker.open(now)
+	}
+
+	return available
+}
+
+// Unwrap returns the first backend, so capabilities which Chain doesn't route itself(listing domains and groups)
+// are served by primary backend
+func (c *Chain) Unwrap() shortener.UrlShortener {
+	if len(c.backends) == 0 {
+		return nil
+	}
+
+	return c.backends[0].Shortener
+}
+
+// BackendFailure reports whether error is caused by backend rather than by request
+//
+// Network errors, rate limits, exhausted quota, rejected token and server errors of API are failures,
+// invalid or conflicting requests and cancellation of request are not
+func BackendFailure(err error) bool {
+	var apiErr *bilty.ApiError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, bilty.ErrAliasTaken), errors.Is(err, bilty.ErrUpgradeRequired), errors.Is(err, bilty.ErrNotFound),
+		errors.Is(err, bilty.ErrLinkExists):
+		return false
+	case errors.Is(err, shortener.ErrAlreadyExists), errors.Is(err, shortener.ErrNotFound),
+		errors.Is(err, shortener.ErrInvalidAlias), errors.Is(err, shortener.ErrInvalidDomain), errors.Is(err, shortener.ErrInvalidGroup):
+		return false
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusForbidden ||
+			apiErr.StatusCode >= http.StatusInternalServerError
+	default:
+		return true
+	}
+}
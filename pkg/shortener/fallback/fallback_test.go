@@ -0,0 +1,169 @@
+package fallback
+
+import (
+	"challenge/pkg/api/bilty"
+	"challenge/pkg/shortener"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// backendMock returns link or error and counts calls
+type backendMock struct {
+	link  string
+	err   error
+	calls int
+}
+
+func (s *backendMock) CreateShortLink(_ string) (string, error) {
+	s.calls++
+	return s.link, s.err
+}
+
+func TestChain_TestCases(t *testing.T) {
+	unavailable := &bilty.ApiError{StatusCode: http.StatusServiceUnavailable, Message: "TEMPORARILY_UNAVAILABLE"}
+
+	tc := []struct {
+		name        string
+		primaryErr  error
+		fallbackErr error
+		wantLink    string
+		wantBackend string
+		wantErr     error
+	}{
+		{
+			name:        "primary",
+			wantLink:    "https://bit.ly/abc",
+			wantBackend: "bitly",
+		},
+		{
+			name:        "primary unavailable",
+			primaryErr:  unavailable,
+			wantLink:    "https://sho.rt/abc",
+			wantBackend: "local",
+		},
+		{
+			name:        "network error",
+			primaryErr:  fmt.Errorf("%w: %v", bilty.ErrInternal, "connection reset"),
+			wantLink:    "https://sho.rt/abc",
+			wantBackend: "local",
+		},
+		{
+			name:       "request error is not passed to fallback",
+			primaryErr: fmt.Errorf("%w: %w", bilty.ErrAliasTaken, &bilty.ApiError{StatusCode: http.StatusConflict}),
+			wantErr:    bilty.ErrAliasTaken,
+		},
+		{
+			name:        "all backends failed",
+			primaryErr:  unavailable,
+			fallbackErr: errors.New("disk is full"),
+			wantErr:     ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &backendMock{link: "https://bit.ly/abc", err: tt.primaryErr}
+			secondary := &backendMock{link: "https://sho.rt/abc", err: tt.fallbackErr}
+			chain := NewChain([]Backend{{Name: "bitly", Shortener: primary}, {Name: "local", Shortener: secondary}})
+
+			ctx, backend := shortener.WithBackendRecorder(context.Background())
+			got, err := chain.CreateShortLinkContext(ctx, "https://www.google.com/")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, backend())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLink, got)
+			assert.Equal(t, tt.wantBackend, backend())
+		})
+	}
+}
+
+func TestChain_AllFailedKeepsUpstreamError(t *testing.T) {
+	chain := NewChain([]Backend{{Name: "bitly", Shortener: &backendMock{err: &bilty.ApiError{StatusCode: http.StatusTooManyRequests}}}})
+
+	_, err := chain.CreateShortLink("https://www.google.com/")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	var apiErr *bilty.ApiError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+}
+
+func TestChain_Breaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	primary := &backendMock{link: "https://bit.ly/abc", err: errors.New("connection refused")}
+	secondary := &backendMock{link: "https://sho.rt/abc"}
+	chain := NewChain(
+		[]Backend{{Name: "bitly", Shortener: primary}, {Name: "local", Shortener: secondary}},
+		WithBreaker(2, time.Minute),
+	)
+	chain.now = func() time.Time { return now }
+
+	// Breaker opens after threshold failures, then primary is skipped
+	for i := 0; i < 4; i++ {
+		got, err := chain.CreateShortLink("https://www.google.com/")
+		require.NoError(t, err)
+		assert.Equal(t, "https://sho.rt/abc", got)
+	}
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, map[string]bool{"bitly": false, "local": true}, chain.Available())
+
+	// After cooldown single trial call is made, its failure opens breaker again
+	now = now.Add(time.Minute)
+	_, err := chain.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.Equal(t, 3, primary.calls)
+	_, err = chain.CreateShortLink("https://www.google.com/")
+	require.NoError(t, err)
+	assert.Equal(t, 3, primary.calls)
+
+	// Successful trial closes breaker
+	now = now.Add(time.Minute)
+	primary.err = nil
+	for i := 0; i < 2; i++ {
+		got, err := chain.CreateShortLink("https://www.google.com/")
+		require.NoError(t, err)
+		assert.Equal(t, "https://bit.ly/abc", got)
+	}
+	assert.Equal(t, 5, primary.calls)
+	assert.Equal(t, map[string]bool{"bitly": true, "local": true}, chain.Available())
+}
+
+func TestChain_Unwrap(t *testing.T) {
+	local := shortener.NewShortener(shortener.NewMemoryStore(), "https://sho.rt")
+	chain := NewChain([]Backend{{Name: "local", Shortener: local}, {Name: "other", Shortener: &backendMock{}}})
+
+	got, ok := shortener.As[*shortener.Shortener](chain)
+	assert.True(t, ok)
+	assert.Same(t, local, got)
+}
+
+func TestBackendFailure_TestCases(t *testing.T) {
+	tc := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "network error", err: fmt.Errorf("%w: %v", bilty.ErrInternal, "timeout"), want: true},
+		{name: "rate limited", err: &bilty.ApiError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "quota exceeded", err: &bilty.ApiError{StatusCode: http.StatusForbidden, Message: "MONTHLY_LIMIT_EXCEEDED"}, want: true},
+		{name: "server error", err: &bilty.ApiError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "bad request", err: &bilty.ApiError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "alias taken", err: shortener.ErrAlreadyExists, want: false},
+		{name: "invalid alias", err: shortener.ErrInvalidAlias, want: false},
+		{name: "canceled", err: fmt.Errorf("%w: %w", bilty.ErrInternal, context.Canceled), want: false},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, BackendFailure(tt.err))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package fallback
+
+import (
+	"challenge/pkg/shortener"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Capabilities of backends which are served by Chain. Links are created with failover,
+// while existing links are managed by backend which owns them, see owner
+
+type customShortener interface {
+	CreateCustomShortLinkContext(ctx context.Context, url string, alias string, domain string, group string) (string, error)
+}
+
+type expiringShortener interface {
+	CreateExpiringShortLinkContext(ctx context.Context, url string, alias string, domain string, group string, expiresAt time.Time) (string, error)
+}
+
+type expander interface {
+	ExpandShortLinkContext(ctx context.Context, url string) (string, error)
+}
+
+type statsProvider interface {
+	GetLinkStatsContext(ctx context.Context, url string, days int, until time.Time) (int, map[string]int, error)
+}
+
+type editor interface {
+	EditLinkContext(ctx context.Context, url string, longUrl string, title *string, tags []string) error
+}
+
+type archiver interface {
+	ArchiveLinkContext(ctx context.Context, url string) error
+}
+
+type deleter interface {
+	DeleteLinkContext(ctx context.Context, url string) error
+}
+
+// CreateCustomShortLinkContext creates link with custom alias, domain and group with the first available backend
+// which supports them
+//
+// ErrUnsupported returned when no backend supports custom links
+func (c *Chain) CreateCustomShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, group string) (string, error) {
+	return create(ctx, c, func(s customShortener) (string, error) {
+		return s.CreateCustomShortLinkContext(ctx, longUrl, alias, domain, group)
+	})
+}
+
+// CreateExpiringShortLinkContext creates expiring link with the first available backend which supports expiration
+//
+// ErrUnsupported returned when no backend supports expiring links
+func (c *Chain) CreateExpiringShortLinkContext(ctx context.Context, longUrl string, alias string, domain string, group string, expiresAt time.Time) (string, error) {
+	return create(ctx, c, func(s expiringShortener) (string, error) {
+		return s.CreateExpiringShortLinkContext(ctx, longUrl, alias, domain, group, expiresAt)
+	})
+}
+
+// ExpandShortLinkContext resolves link with backend which owns it
+func (c *Chain) ExpandShortLinkContext(ctx context.Context, shortUrl string) (string, error) {
+	b := c.owner(shortUrl)
+	e, ok := shortener.As[expander](b.Shortener)
+	if !ok {
+		return "", unsupported(b, "expanding links")
+	}
+
+	return e.ExpandShortLinkContext(ctx, shortUrl)
+}
+
+// GetLinkStatsContext counts clicks of link with backend which owns it
+func (c *Chain) GetLinkStatsContext(ctx context.Context, shortUrl string, days int, until time.Time) (int, map[string]int, error) {
+	b := c.owner(shortUrl)
+	p, ok := shortener.As[statsProvider](b.Shortener)
+	if !ok {
+		return 0, nil, unsupported(b, "link stats")
+	}
+
+	return p.GetLinkStatsContext(ctx, shortUrl, days, until)
+}
+
+// EditLinkContext changes long url, title and tags of link with backend which owns it
+func (c *Chain) EditLinkContext(ctx context.Context, shortUrl string, longUrl string, title *string, tags []string) error {
+	b := c.owner(shortUrl)
+	e, ok := shortener.As[editor](b.Shortener)
+	if !ok {
+		return unsupported(b, "changing links")
+	}
+
+	return e.EditLinkContext(ctx, shortUrl, longUrl, title, tags)
+}
+
+// ArchiveLinkContext archives link with backend which owns it
+func (c *Chain) ArchiveLinkContext(ctx context.Context, shortUrl string) error {
+	b := c.owner(shortUrl)
+	a, ok := shortener.As[archiver](b.Shortener)
+	if !ok {
+		return unsupported(b, "archiving links")
+	}
+
+	return a.ArchiveLinkContext(ctx, shortUrl)
+}
+
+// DeleteLinkContext deletes link with backend which owns it
+func (c *Chain) DeleteLinkContext(ctx context.Context, shortUrl string) error {
+	b := c.owner(shortUrl)
+	d, ok := shortener.As[deleter](b.Shortener)
+	if !ok {
+		return unsupported(b, "deleting links")
+	}
+
+	return d.DeleteLinkContext(ctx, shortUrl)
+}
+
+// owner returns backend which created link: the first backend which claims it(see shortener.LinkOwner),
+// otherwise the first backend which can't tell its links, e.g. Bitly with branded domains, otherwise primary one
+func (c *Chain) owner(shortUrl string) *backend {
+	var unknown *backend
+	for _, b := range c.backends {
+		o, ok := shortener.As[shortener.LinkOwner](b.Shortener)
+		if !ok {
+			if unknown == nil {
+				unknown = b
+			}
+			continue
+		}
+		if o.OwnsLink(shortUrl) {
+			return b
+		}
+	}
+	if unknown != nil {
+		return unknown
+	}
+
+	return c.backends[0]
+}
+
+func unsupported(b *backend, capability string) error {
+	return fmt.Errorf("%w: %s doesn't support %s", ErrUnsupported, b.Name, capability)
+}
@@ -0,0 +1,130 @@
+package fallback
+
+import (
+	"challenge/pkg/api/bilty"
+	"challenge/pkg/api/bilty/bitlytest"
+	"challenge/pkg/shortener"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newBitlyAndLocal creates chain of Bitly emulator with local fallback
+func newBitlyAndLocal(t *testing.T, opts ...bilty.Option) (*Chain, *bitlytest.Server, shortener.Store) {
+	t.Helper()
+	srv := bitlytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	opts = append([]bilty.Option{bilty.WithBaseUrl(srv.URL)}, opts...)
+	store := shortener.NewMemoryStore()
+	chain := NewChain([]Backend{
+		{Name: "bitly", Shortener: bilty.NewBilty(bitlytest.DefaultToken, srv.Client(), opts...)},
+		{Name: "local", Shortener: shortener.NewShortener(store, "https://sho.rt")},
+	})
+	return chain, srv, store
+}
+
+func TestChain_ManagesFallbackLinks(t *testing.T) {
+	chain, srv, store := newBitlyAndLocal(t)
+	ctx := context.Background()
+
+	// Link is created by fallback while Bitly is down, it must be managed by fallback after Bitly is back
+	srv.Fail(bitlytest.Failure{Path: "/v4/shorten", Times: 1, StatusCode: http.StatusServiceUnavailable, Message: "TEMPORARILY_UNAVAILABLE"})
+	local, err := chain.CreateCustomShortLinkContext(ctx, "https://www.google.com/", "campaign", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://sho.rt/campaign", local)
+
+	long, err := chain.ExpandShortLinkContext(ctx, local)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/", long)
+
+	title := "Search"
+	require.NoError(t, chain.EditLinkContext(ctx, local, "https://github.com/", &title, []string{"a"}))
+	require.NoError(t, chain.ArchiveLinkContext(ctx, local))
+	link, err := store.Get("campaign")
+	require.NoError(t, err)
+	assert.Equal(t, "Search", link.Title)
+	assert.Equal(t, "https://github.com/", link.LongUrl)
+	assert.True(t, link.Archived)
+
+	total, _, err := chain.GetLinkStatsContext(ctx, local, 7, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	require.NoError(t, chain.DeleteLinkContext(ctx, local))
+	_, err = store.Get("campaign")
+	assert.ErrorIs(t, err, shortener.ErrNotFound)
+
+	// Links which no backend claims are managed by Bitly
+	bitlink, err := chain.CreateShortLinkContext(ctx, "https://www.google.com/")
+	require.NoError(t, err)
+	long, err = chain.ExpandShortLinkContext(ctx, bitlink)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/", long)
+	_, err = chain.ExpandShortLinkContext(ctx, "https://sho.rt/unknown")
+	assert.ErrorIs(t, err, shortener.ErrNotFound)
+}
+
+func TestChain_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	// Bitly plan without expiration passes expiring link to fallback, breaker isn't affected
+	chain, _, store := newBitlyAndLocal(t, bilty.WithExpiration(false))
+	link, err := chain.CreateExpiringShortLinkContext(ctx, "https://www.google.com/", "", "", "", expiresAt)
+	require.NoError(t, err)
+	slug, err := shortener.NewShortener(store, "https://sho.rt").Slug(link)
+	require.NoError(t, err)
+	saved, err := store.Get(slug)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt.Unix(), saved.ExpiresAt.Unix())
+	assert.Equal(t, map[string]bool{"bitly": true, "local": true}, chain.Available())
+
+	// Backends without capability are reported as unsupported
+	chain = NewChain([]Backend{{Name: "bitly", Shortener: &backendMock{}}, {Name: "other", Shortener: &backendMock{}}})
+	_, err = chain.CreateCustomShortLinkContext(ctx, "https://www.google.com/", "campaign", "", "")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, err = chain.ExpandShortLinkContext(ctx, "https://bit.ly/abc")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorIs(t, chain.DeleteLinkContext(ctx, "https://bit.ly/abc"), ErrUnsupported)
+}
+
+func TestChain_SkipsBackendWithoutOptions(t *testing.T) {
+	ctx := context.Background()
+	outage := bitlytest.Failure{Path: "/v4/shorten", StatusCode: http.StatusServiceUnavailable, Message: "TEMPORARILY_UNAVAILABLE"}
+
+	tc := []struct {
+		name   string
+		domain string
+		group  string
+	}{
+		{name: "group", group: "Bk123"},
+		{name: "other domain", domain: "bit.ly"},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			// Self-hosted fallback can't serve group and other domain, so Bitly outage is reported as is
+			chain, srv, _ := newBitlyAndLocal(t, bilty.WithRetry(0, 0, 0))
+			srv.Fail(outage)
+			_, err := chain.CreateCustomShortLinkContext(ctx, "https://www.google.com/", "", tt.domain, tt.group)
+			assert.ErrorIs(t, err, ErrUnavailable)
+			assert.True(t, chain.Available()["local"])
+
+			// Self-hosted primary passes such requests to Bitly
+			store := shortener.NewMemoryStore()
+			chain = NewChain([]Backend{
+				{Name: "local", Shortener: shortener.NewShortener(store, "https://sho.rt")},
+				{Name: "bitly", Shortener: chain.backends[0].Shortener},
+			}, WithBreaker(1, time.Hour))
+			srv.ResetFailures()
+			link, err := chain.CreateCustomShortLinkContext(ctx, "https://www.google.com/", "", tt.domain, tt.group)
+			require.NoError(t, err)
+			assert.Contains(t, link, "bit.ly/")
+			assert.Equal(t, map[string]bool{"local": true, "bitly": true}, chain.Available())
+		})
+	}
+}
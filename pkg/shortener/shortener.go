@@ -0,0 +1,361 @@
+// Package shortener provides self-hosted link shortening without any external API
+// Links are kept in pluggable Store and returned under configured base url
+package shortener
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInternal          = errors.New("internal shortener error")
+	ErrNotFound          = errors.New("link not found")
+	ErrAlreadyExists     = errors.New("link already exists")
+	ErrInvalidAlias      = errors.New("invalid alias")
+	ErrInvalidDomain     = errors.New("invalid domain")
+	ErrInvalidGroup      = errors.New("groups are not supported")
+	ErrInvalidExpiration = errors.New("invalid expiration")
+)
+
+const (
+	alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	defaultSlugLength = 7
+	maxAttempts       = 10
+
+	dateLayout = "2006-01-02"
+)
+
+var aliasRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// UrlShortener is common interface of link shortening backends and their decorators
+type UrlShortener interface {
+	CreateShortLink(url string) (string, error)
+}
+
+// ContextUrlShortener is implemented by shorteners which stop link creation when context is done
+type ContextUrlShortener interface {
+	CreateShortLinkContext(ctx context.Context, url string) (string, error)
+}
+
+// CreateShortLinkContext creates link with context-aware method of s if it has one
+//
+// Unlike As, only s itself is checked, so decorators in the chain are never skipped
+func CreateShortLinkContext(ctx context.Context, s UrlShortener, url string) (string, error) {
+	if cs, ok := s.(ContextUrlShortener); ok {
+		return cs.CreateShortLinkContext(ctx, url)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return s.CreateShortLink(url)
+}
+
+// LinkOwner is implemented by shorteners which can tell whether short link was created by them,
+// e.g. by base url of the link
+type LinkOwner interface {
+	OwnsLink(shortUrl string) bool
+}
+
+// Wrapper is implemented by UrlShortener decorators, Unwrap returns decorated shortener
+type Wrapper interface {
+	Unwrap() UrlShortener
+}
+
+// As finds first shortener in the chain of decorators which implements T
+//
+// Decorators usually implement only CreateShortLink, so As is used to reach
+// optional capabilities(custom links, expanding, stats) of the backend behind them
+func As[T any](s UrlShortener) (T, bool) {
+	for s != nil {
+		if t, ok := s.(T); ok {
+			return t, true
+		}
+		w, ok := s.(Wrapper)
+		if !ok {
+			break
+		}
+		s = w.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
+type Shortener struct {
+	store   Store
+	baseUrl string
+	slugLen int
+}
+
+func NewShortener(store Store, baseUrl string) *Shortener {
+	return &Shortener{
+		store:   store,
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		slugLen: defaultSlugLength,
+	}
+}
+
+// CreateShortLink creates a short link for the given long URL.
+//
+// Slug of the link is generated randomly, generation is repeated when slug is already taken,
+// so returned link is always unique within the store
+func (s *Shortener) CreateShortLink(longUrl string) (string, error) {
+	return s.generate(longUrl, time.Time{})
+}
+
+// generate saves link with randomly generated slug, see CreateShortLink
+func (s *Shortener) generate(longUrl string, expiresAt time.Time) (string, error) {
+	for i := 0; i < maxAttempts; i++ {
+		slug, err := generateSlug(s.slugLen)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+
+		err = s.store.Save(Link{
+			Slug:      slug,
+			LongUrl:   longUrl,
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		})
+		if errors.Is(err, ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return s.ShortUrl(slug), nil
+	}
+
+	return "", fmt.Errorf("%w: %v", ErrInternal, "couldn't generate unique slug")
+}
+
+// CreateCustomShortLink creates a short link for the given long URL with custom alias used as slug
+//
+// Self-hosted links are served only under base url, so non-empty domain must match base url host.
+// Self-hosted links have no groups, so group must be empty.
+// If alias is empty, link with generated slug is created.
+//
+// ErrInvalidAlias returned when alias contains anything except letters, digits, '-' and '_'
+// ErrInvalidDomain returned when domain differs from base url host
+// ErrInvalidGroup returned when group is not empty
+// ErrAlreadyExists returned when alias is already taken
+func (s *Shortener) CreateCustomShortLink(longUrl string, alias string, domain string, group string) (string, error) {
+	return s.CreateExpiringShortLink(longUrl, alias, domain, group, time.Time{})
+}
+
+// CreateExpiringShortLink is CreateCustomShortLink which creates link that stops redirecting at expiresAt,
+// zero expiresAt means link never expires
+//
+// ErrInvalidExpiration returned when expiresAt is in the past
+func (s *Shortener) CreateExpiringShortLink(longUrl string, alias string, domain string, group string, expiresAt time.Time) (string, error) {
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return "", fmt.Errorf("%w: %v is in the past", ErrInvalidExpiration, expiresAt.Format(time.RFC3339))
+	}
+	if group != "" {
+		return "", ErrInvalidGroup
+	}
+	if domain != "" {
+		base, err := url.Parse(s.baseUrl)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+		if !strings.EqualFold(domain, base.Host) {
+			return "", fmt.Errorf("%w: only %s is served", ErrInvalidDomain, base.Host)
+		}
+	}
+
+	if alias == "" {
+		return s.generate(longUrl, expiresAt)
+	}
+	if !aliasRegexp.MatchString(alias) {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAlias, alias)
+	}
+
+	err := s.store.Save(Link{
+		Slug:      alias,
+		LongUrl:   longUrl,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.ShortUrl(alias), nil
+}
+
+// ListDomains returns the only domain of self-hosted links, host of base url
+func (s *Shortener) ListDomains() ([]string, error) {
+	base, err := url.Parse(s.baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return []string{base.Host}, nil
+}
+
+// ExpandShortLink returns long URL the given short link points to
+//
+// ErrInvalidDomain returned when link doesn't belong to base url of this shortener
+// ErrNotFound returned when there is no such link in the store
+func (s *Shortener) ExpandShortLink(shortUrl string) (string, error) {
+	slug, err := s.Slug(shortUrl)
+	if err != nil {
+		return "", err
+	}
+
+	link, err := s.store.Get(slug)
+	if err != nil {
+		return "", err
+	}
+
+	return link.LongUrl, nil
+}
+
+// UpdateLink changes title and tags of the given short link
+//
+// nil title or tags are kept unchanged, empty non-nil tags remove all tags of the link
+//
+// ErrInvalidDomain returned when link doesn't belong to base url of this shortener
+// ErrNotFound returned when there is no such link in the store
+func (s *Shortener) UpdateLink(shortUrl string, title *string, tags []string) error {
+	return s.EditLink(shortUrl, "", title, tags)
+}
+
+// RetargetLink points the given short link at new long URL, clicks of the link are kept
+func (s *Shortener) RetargetLink(shortUrl string, longUrl string) error {
+	return s.EditLink(shortUrl, longUrl, nil, nil)
+}
+
+// EditLink changes long url, title and tags of the given short link with single update of the store,
+// so either all of them are changed or none
+//
+// Empty long url and nil title or tags are kept unchanged, empty non-nil tags remove all tags of the link
+func (s *Shortener) EditLink(shortUrl string, longUrl string, title *string, tags []string) error {
+	return s.update(shortUrl, func(link *Link) {
+		if longUrl != "" {
+			link.LongUrl = longUrl
+		}
+		if title != nil {
+			link.Title = *title
+		}
+		if tags != nil {
+			link.Tags = tags
+		}
+	})
+}
+
+// ArchiveLink stops redirects of the given short link, link and its clicks are kept in the store,
+// so its slug can't be reused
+func (s *Shortener) ArchiveLink(shortUrl string) error {
+	return s.update(shortUrl, func(link *Link) {
+		link.Archived = true
+	})
+}
+
+// DeleteLink removes the given short link with its clicks, so its slug can be reused
+func (s *Shortener) DeleteLink(shortUrl string) error {
+	slug, err := s.Slug(shortUrl)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Delete(slug)
+}
+
+func (s *Shortener) update(shortUrl string, update func(link *Link)) error {
+	slug, err := s.Slug(shortUrl)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.Update(slug, update)
+	return err
+}
+
+// GetLinkStats returns total clicks and clicks per day(in YYYY-MM-DD format)
+// of the given short link over window of given amount of days which ends at until
+//
+// Every day of the window is present in returned map, even without clicks.
+// If days is not positive, all recorded clicks are returned
+func (s *Shortener) GetLinkStats(shortUrl string, days int, until time.Time) (int, map[string]int, error) {
+	slug, err := s.Slug(shortUrl)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	link, err := s.store.Get(slug)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if days <= 0 {
+		total := 0
+		for _, c := range link.Clicks {
+			total += c
+		}
+		if link.Clicks == nil {
+			link.Clicks = make(map[string]int)
+		}
+		return total, link.Clicks, nil
+	}
+
+	total := 0
+	daily := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		date := until.UTC().AddDate(0, 0, -i).Format(dateLayout)
+		daily[date] = link.Clicks[date]
+		total += link.Clicks[date]
+	}
+
+	return total, daily, nil
+}
+
+// Slug extracts slug from the given short link
+//
+// ErrInvalidDomain returned when link doesn't belong to base url of this shortener
+func (s *Shortener) Slug(shortUrl string) (string, error) {
+	slug, ok := strings.CutPrefix(shortUrl, s.baseUrl+"/")
+	if !ok || slug == "" || strings.Contains(slug, "/") {
+		return "", fmt.Errorf("%w: %s doesn't belong to %s", ErrInvalidDomain, shortUrl, s.baseUrl)
+	}
+
+	return slug, nil
+}
+
+// OwnsLink reports whether the given short link belongs to base url of this shortener
+func (s *Shortener) OwnsLink(shortUrl string) bool {
+	_, err := s.Slug(shortUrl)
+	return err == nil
+}
+
+// ShortUrl returns full short url for the given slug
+func (s *Shortener) ShortUrl(slug string) string {
+	return s.baseUrl + "/" + slug
+}
+
+func generateSlug(length int) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+
+	var sb strings.Builder
+	sb.Grow(length)
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
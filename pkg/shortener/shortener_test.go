@@ -0,0 +1,449 @@
+package shortener
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collidingStore reports first saves as already existing to simulate slug collisions
+type collidingStore struct {
+	*MemoryStore
+	collisions int
+}
+
+func (s *collidingStore) Save(link Link) error {
+	if s.collisions > 0 {
+		s.collisions--
+		return ErrAlreadyExists
+	}
+	return s.MemoryStore.Save(link)
+}
+
+func TestCreateShortLink_TestCases(t *testing.T) {
+	tc := []struct {
+		name       string
+		baseUrl    string
+		collisions int
+		wantPrefix string
+		wantErr    bool
+	}{
+		{
+			name:       "ok",
+			baseUrl:    "http://localhost:8080",
+			wantPrefix: "http://localhost:8080/",
+		},
+		{
+			name:       "ok, trailing slash in base url",
+			baseUrl:    "https://sho.rt/",
+			wantPrefix: "https://sho.rt/",
+		},
+		{
+			name:       "ok, after collisions",
+			baseUrl:    "https://sho.rt",
+			collisions: maxAttempts - 1,
+			wantPrefix: "https://sho.rt/",
+		},
+		{
+			name:       "too many collisions",
+			baseUrl:    "https://sho.rt",
+			collisions: maxAttempts,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &collidingStore{MemoryStore: NewMemoryStore(), collisions: tt.collisions}
+			s := NewShortener(store, tt.baseUrl)
+
+			got, err := s.CreateShortLink("https://www.google.com/")
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInternal)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(got, tt.wantPrefix))
+
+			slug := strings.TrimPrefix(got, tt.wantPrefix)
+			assert.Len(t, slug, defaultSlugLength)
+			link, err := store.Get(slug)
+			require.NoError(t, err)
+			assert.Equal(t, "https://www.google.com/", link.LongUrl)
+		})
+	}
+}
+
+func TestCreateShortLink_Unique(t *testing.T) {
+	s := NewShortener(NewMemoryStore(), "https://sho.rt")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		got, err := s.CreateShortLink("https://www.google.com/")
+		require.NoError(t, err)
+		require.False(t, seen[got], "duplicated link: %s", got)
+		seen[got] = true
+	}
+}
+
+func TestFileStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "links.json")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(Link{Slug: "abc", LongUrl: "https://www.google.com/"}))
+	assert.ErrorIs(t, store.Save(Link{Slug: "abc", LongUrl: "https://github.com/"}), ErrAlreadyExists)
+
+	// Reopen store, link must be loaded from file
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	link, err := reopened.Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/", link.LongUrl)
+
+	_, err = reopened.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCreateCustomShortLink_TestCases(t *testing.T) {
+	tc := []struct {
+		name    string
+		alias   string
+		domain  string
+		group   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "ok",
+			alias: "campaign-2024",
+			want:  "https://sho.rt/campaign-2024",
+		},
+		{
+			name:   "ok, matching domain",
+			alias:  "docs",
+			domain: "SHO.RT",
+			want:   "https://sho.rt/docs",
+		},
+		{
+			name:    "taken alias",
+			alias:   "taken",
+			wantErr: ErrAlreadyExists,
+		},
+		{
+			name:    "invalid alias",
+			alias:   "not/valid",
+			wantErr: ErrInvalidAlias,
+		},
+		{
+			name:    "foreign domain",
+			alias:   "other",
+			domain:  "bit.ly",
+			wantErr: ErrInvalidDomain,
+		},
+		{
+			name:    "group",
+			alias:   "grouped",
+			group:   "Ba1",
+			wantErr: ErrInvalidGroup,
+		},
+	}
+
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(Link{Slug: "taken", LongUrl: "https://github.com/"}))
+	s := NewShortener(store, "https://sho.rt")
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.CreateCustomShortLink("https://www.google.com/", tt.alias, tt.domain, tt.group)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandShortLink_TestCases(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(Link{Slug: "abc", LongUrl: "https://www.google.com/"}))
+	s := NewShortener(store, "https://sho.rt")
+
+	tc := []struct {
+		name     string
+		shortUrl string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "ok",
+			shortUrl: "https://sho.rt/abc",
+			want:     "https://www.google.com/",
+		},
+		{
+			name:     "unknown slug",
+			shortUrl: "https://sho.rt/unknown",
+			wantErr:  ErrNotFound,
+		},
+		{
+			name:     "foreign link",
+			shortUrl: "https://bit.ly/abc",
+			wantErr:  ErrInvalidDomain,
+		},
+		{
+			name:     "empty slug",
+			shortUrl: "https://sho.rt/",
+			wantErr:  ErrInvalidDomain,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ExpandShortLink(tt.shortUrl)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetLinkStats_TestCases(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(Link{Slug: "abc", LongUrl: "https://www.google.com/"}))
+	until := time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{until, until, until.AddDate(0, 0, -1), until.AddDate(0, 0, -5)} {
+		require.NoError(t, store.RecordClick("abc", at))
+	}
+	s := NewShortener(store, "https://sho.rt")
+
+	tc := []struct {
+		name      string
+		shortUrl  string
+		days      int
+		wantTotal int
+		wantDaily map[string]int
+		wantErr   error
+	}{
+		{
+			name:      "ok, window",
+			shortUrl:  "https://sho.rt/abc",
+			days:      3,
+			wantTotal: 3,
+			wantDaily: map[string]int{"2024-03-03": 2, "2024-03-02": 1, "2024-03-01": 0},
+		},
+		{
+			name:      "ok, all time",
+			shortUrl:  "https://sho.rt/abc",
+			days:      0,
+			wantTotal: 4,
+			wantDaily: map[string]int{"2024-03-03": 2, "2024-03-02": 1, "2024-02-27": 1},
+		},
+		{
+			name:     "unknown link",
+			shortUrl: "https://sho.rt/unknown",
+			days:     3,
+			wantErr:  ErrNotFound,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			total, daily, err := s.GetLinkStats(tt.shortUrl, tt.days, until)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.Equal(t, tt.wantDaily, daily)
+		})
+	}
+}
+
+type decorator struct {
+	next UrlShortener
+}
+
+func (d decorator) CreateShortLink(url string) (string, error) {
+	return d.next.CreateShortLink(url)
+}
+
+func (d decorator) Unwrap() UrlShortener {
+	return d.next
+}
+
+func TestAs(t *testing.T) {
+	type expander interface {
+		ExpandShortLink(url string) (string, error)
+	}
+	type unknown interface {
+		Unknown()
+	}
+
+	backend := NewShortener(NewMemoryStore(), "https://sho.rt")
+	chain := decorator{next: decorator{next: backend}}
+
+	got, ok := As[expander](chain)
+	require.True(t, ok)
+	assert.Equal(t, backend, got)
+
+	_, ok = As[unknown](chain)
+	assert.False(t, ok)
+}
+
+func TestFileStore_UpdateDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	s := NewShortener(store, "https://sho.rt")
+	link, err := s.CreateCustomShortLink("https://www.google.com/", "abc", "", "")
+	require.NoError(t, err)
+	_, err = s.CreateCustomShortLink("https://www.google.com/", "removed", "", "")
+	require.NoError(t, err)
+
+	title := "Search"
+	require.NoError(t, s.UpdateLink(link, &title, []string{"a"}))
+	require.NoError(t, s.RetargetLink(link, "https://github.com/"))
+	require.NoError(t, s.ArchiveLink(link))
+	require.NoError(t, s.DeleteLink("https://sho.rt/removed"))
+	assert.ErrorIs(t, s.DeleteLink("https://sho.rt/removed"), ErrNotFound)
+	assert.ErrorIs(t, s.ArchiveLink("https://bit.ly/abc"), ErrInvalidDomain)
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	got, err := reopened.Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/", got.LongUrl)
+	assert.Equal(t, "Search", got.Title)
+	assert.Equal(t, []string{"a"}, got.Tags)
+	assert.True(t, got.Archived)
+	_, err = reopened.Get("removed")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStore_Clicks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clicks := func() int {
+		t.Helper()
+		reopened, err := NewFileStore(path)
+		require.NoError(t, err)
+		link, err := reopened.Get("abc")
+		require.NoError(t, err)
+		return link.Clicks["2024-01-01"]
+	}
+
+	store, err := NewFileStore(path, WithClickBatch(3))
+	require.NoError(t, err)
+	require.NoError(t, store.Save(Link{Slug: "abc", LongUrl: "https://www.google.com/"}))
+
+	// Clicks are kept in memory until batch is full
+	for range 2 {
+		require.NoError(t, store.RecordClick("abc", at))
+	}
+	link, err := store.Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, 2, link.Clicks["2024-01-01"])
+	assert.Equal(t, 0, clicks())
+
+	require.NoError(t, store.RecordClick("abc", at))
+	assert.Equal(t, 3, clicks())
+
+	// Pending clicks are persisted by timer and on close
+	require.NoError(t, store.RecordClick("abc", at))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.SyncClicks(ctx, time.Millisecond)
+	require.Eventually(t, func() bool {
+		return clicks() == 4
+	}, time.Second, time.Millisecond)
+	cancel()
+
+	require.NoError(t, store.RecordClick("abc", at))
+	require.NoError(t, store.Close())
+	assert.Equal(t, 5, clicks())
+}
+
+func TestCreateExpiringShortLink(t *testing.T) {
+	store := NewMemoryStore()
+	s := NewShortener(store, "https://sho.rt")
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	link, err := s.CreateExpiringShortLink("https://www.google.com/", "campaign", "", "", expiresAt)
+	require.NoError(t, err)
+	got, err := store.Get("campaign")
+	require.NoError(t, err)
+	assert.Equal(t, "https://sho.rt/campaign", link)
+	assert.True(t, expiresAt.Equal(got.ExpiresAt))
+
+	link, err = s.CreateExpiringShortLink("https://www.google.com/", "", "", "", expiresAt)
+	require.NoError(t, err)
+	got, err = store.Get(strings.TrimPrefix(link, "https://sho.rt/"))
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(got.ExpiresAt))
+
+	_, err = s.CreateExpiringShortLink("https://www.google.com/", "past", "", "", time.Now().Add(-time.Second))
+	assert.ErrorIs(t, err, ErrInvalidExpiration)
+}
+
+func TestDeleteExpired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "links.json")
+	fileStore, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	for _, store := range []Store{NewMemoryStore(), fileStore} {
+		require.NoError(t, store.Save(Link{Slug: "forever", LongUrl: "https://www.google.com/"}))
+		require.NoError(t, store.Save(Link{Slug: "expired", LongUrl: "https://www.google.com/", ExpiresAt: now.Add(-time.Minute)}))
+		require.NoError(t, store.Save(Link{Slug: "active", LongUrl: "https://www.google.com/", ExpiresAt: now.Add(time.Minute)}))
+
+		n, err := store.DeleteExpired(now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		_, err = store.Get("expired")
+		assert.ErrorIs(t, err, ErrNotFound)
+		for _, slug := range []string{"forever", "active"} {
+			_, err := store.Get(slug)
+			assert.NoError(t, err)
+		}
+	}
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	_, err = reopened.Get("expired")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSweep(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(Link{Slug: "expired", LongUrl: "https://www.google.com/", ExpiresAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, store.Save(Link{Slug: "retained", LongUrl: "https://www.google.com/", ExpiresAt: time.Now()}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Sweep(ctx, store, time.Millisecond, time.Minute)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := store.Get("expired")
+		return err != nil
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// Recently expired link is kept during retention
+	_, err := store.Get("retained")
+	assert.NoError(t, err)
+}
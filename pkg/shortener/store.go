@@ -0,0 +1,384 @@
+package shortener
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Link struct {
+	Slug      string    `json:"slug"`
+	LongUrl   string    `json:"long_url"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is zero for links that never expire
+	ExpiresAt time.Time `json:"expires_at"`
+	Title     string    `json:"title,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	// Archived links are kept with their stats, but don't redirect anymore
+	Archived bool `json:"archived,omitempty"`
+	// Clicks is amount of redirects per day(UTC) in YYYY-MM-DD format
+	Clicks map[string]int `json:"clicks,omitempty"`
+}
+
+// Expired reports whether link is expired at given moment
+func (l Link) Expired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt)
+}
+
+// Store is a storage of shortened links
+//
+// Save must return ErrAlreadyExists if link with the same slug is already stored
+// Get, RecordClick, Update and Delete must return ErrNotFound if there is no link with given slug
+//
+// Update applies given function to stored link and returns updated link
+// DeleteExpired removes links which expired before given moment and returns amount of removed links
+type Store interface {
+	Save(link Link) error
+	Get(slug string) (Link, error)
+	RecordClick(slug string, at time.Time) error
+	Update(slug string, update func(link *Link)) (Link, error)
+	Delete(slug string) error
+	DeleteExpired(before time.Time) (int, error)
+}
+
+// MemoryStore keeps links in memory, all links are lost on restart
+type MemoryStore struct {
+	mu    sync.RWMutex
+	links map[string]Link
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		links: make(map[string]Link),
+	}
+}
+
+func (s *MemoryStore) Save(link Link) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[link.Slug]; ok {
+		return fmt.Errorf("%w: %v", ErrAlreadyExists, link.Slug)
+	}
+	s.links[link.Slug] = link
+
+	return nil
+}
+
+func (s *MemoryStore) Get(slug string) (Link, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	link, ok := s.links[slug]
+	if !ok {
+		return Link{}, fmt.Errorf("%w: %v", ErrNotFound, slug)
+	}
+	return clone(link), nil
+}
+
+func (s *MemoryStore) RecordClick(slug string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[slug]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrNotFound, slug)
+	}
+	if link.Clicks == nil {
+		link.Clicks = make(map[string]int)
+		s.links[slug] = link
+	}
+	link.Clicks[at.UTC().Format(dateLayout)]++
+
+	return nil
+}
+
+func (s *MemoryStore) Update(slug string, update func(link *Link)) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, ok := s.links[slug]
+	if !ok {
+		return Link{}, fmt.Errorf("%w: %v", ErrNotFound, slug)
+	}
+	update(&link)
+	link.Slug = slug
+	s.links[slug] = link
+
+	return clone(link), nil
+}
+
+func (s *MemoryStore) Delete(slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[slug]; !ok {
+		return fmt.Errorf("%w: %v", ErrNotFound, slug)
+	}
+	delete(s.links, slug)
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.links)
+	maps.DeleteFunc(s.links, func(_ string, link Link) bool {
+		return link.Expired(before)
+	})
+
+	return n - len(s.links), nil
+}
+
+func (s *MemoryStore) remove(slug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.links, slug)
+}
+
+// put replaces stored link unconditionally, used to roll back failed changes
+func (s *MemoryStore) put(link Link) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.links[link.Slug] = link
+}
+
+// clone copies mutable fields of the link, clicks are updated in place, so caller must get its own copy
+func clone(link Link) Link {
+	link.Clicks = maps.Clone(link.Clicks)
+	link.Tags = slices.Clone(link.Tags)
+	return link
+}
+
+// defaultClickBatch is number of clicks after which they are persisted without waiting for SyncClicks
+const defaultClickBatch = 100
+
+// FileStore keeps links in memory and persists all of them to json file on every change
+//
+// Clicks are persisted in batches, see WithClickBatch, SyncClicks and Close, so redirects don't rewrite the file.
+// Existing file is loaded on creation, so links survive restarts
+type FileStore struct {
+	mem        *MemoryStore
+	path       string
+	clickBatch int
+	mu         sync.Mutex
+	// clicks is number of clicks recorded in memory since the last flush
+	clicks int
+}
+
+// FileStoreOption configures FileStore
+type FileStoreOption func(s *FileStore)
+
+// WithClickBatch sets number of clicks which are kept in memory before they are persisted,
+// not positive value means default
+func WithClickBatch(n int) FileStoreOption {
+	return func(s *FileStore) {
+		if n > 0 {
+			s.clickBatch = n
+		}
+	}
+}
+
+// NewFileStore creates store bound to file in given path
+//
+// If file not exists, it will be created on first save
+func NewFileStore(path string, opts ...FileStoreOption) (*FileStore, error) {
+	s := &FileStore{
+		mem:        NewMemoryStore(),
+		path:       path,
+		clickBatch: defaultClickBatch,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	bts, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if len(bts) != 0 {
+		if err := json.Unmarshal(bts, &s.mem.links); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Save(link Link) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mem.Save(link); err != nil {
+		return err
+	}
+
+	if err := s.flush(); err != nil {
+		// Keep memory consistent with file if link couldn't be persisted
+		s.mem.remove(link.Slug)
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) Get(slug string) (Link, error) {
+	return s.mem.Get(slug)
+}
+
+func (s *FileStore) RecordClick(slug string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mem.RecordClick(slug, at); err != nil {
+		return err
+	}
+
+	// Click is already counted in memory, failed flush is retried with the next batch
+	s.clicks++
+	if s.clicks < s.clickBatch {
+		return nil
+	}
+
+	return s.flush()
+}
+
+// SyncClicks persists pending clicks every interval until ctx is done
+func (s *FileStore) SyncClicks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sync(); err != nil {
+				log.Printf("failed to persist clicks. err: %v\n", err)
+			}
+		}
+	}
+}
+
+// Sync persists clicks which are kept in memory
+func (s *FileStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clicks == 0 {
+		return nil
+	}
+
+	return s.flush()
+}
+
+// Close persists pending clicks, store must not be used after it
+func (s *FileStore) Close() error {
+	return s.Sync()
+}
+
+func (s *FileStore) Update(slug string, update func(link *Link)) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.mem.Get(slug)
+	if err != nil {
+		return Link{}, err
+	}
+	link, err := s.mem.Update(slug, update)
+	if err != nil {
+		return Link{}, err
+	}
+
+	if err := s.flush(); err != nil {
+		s.mem.put(old)
+		return Link{}, err
+	}
+
+	return link, nil
+}
+
+func (s *FileStore) Delete(slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.mem.Get(slug)
+	if err != nil {
+		return err
+	}
+	if err := s.mem.Delete(slug); err != nil {
+		return err
+	}
+
+	if err := s.flush(); err != nil {
+		s.mem.put(old)
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) DeleteExpired(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mem.mu.RLock()
+	old := maps.Clone(s.mem.links)
+	s.mem.mu.RUnlock()
+
+	n, err := s.mem.DeleteExpired(before)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	if err := s.flush(); err != nil {
+		s.mem.mu.Lock()
+		s.mem.links = old
+		s.mem.mu.Unlock()
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// flush writes all links to temporary file and then replaces store file with it,
+// so store file never stays partially written
+func (s *FileStore) flush() error {
+	s.mem.mu.RLock()
+	bts, err := json.Marshal(s.mem.links)
+	s.mem.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, bts, 0o644); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	s.clicks = 0
+
+	return nil
+}
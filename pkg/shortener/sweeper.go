@@ -0,0 +1,32 @@
+package shortener
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Sweep purges expired links from store every interval until ctx is done
+//
+// Links are kept for retention after expiration, so their short urls respond 410 Gone
+// for a while instead of 404 Not Found
+func Sweep(ctx context.Context, store Store, interval time.Duration, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := store.DeleteExpired(now.Add(-retention))
+			if err != nil {
+				log.Printf("failed to purge expired links. err: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("purged %d expired links\n", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSlowConsumer = errors.New("subscriber is too slow")
+)
+
+// SlowPolicy decides what happens to ping when queue of subscriber is full
+type SlowPolicy string
+
+const (
+	// PolicyDropOldest drops the oldest queued ping to make room for the new one
+	PolicyDropOldest SlowPolicy = "drop_oldest"
+	// PolicyDropNewest drops the new ping
+	PolicyDropNewest SlowPolicy = "drop_newest"
+	// PolicyCoalesce keeps only the latest ping, queue size is ignored
+	PolicyCoalesce SlowPolicy = "coalesce"
+	// PolicyDisconnect closes channel of subscriber
+	PolicyDisconnect SlowPolicy = "disconnect"
+
+	defaultQueueSize = 8
+)
+
+// Broker fans out pings of timers to their subscribers, it is safe for concurrent use
+//
+// Every subscriber receives pings at its own rate, see Subscribe, and has buffered queue, so publisher never
+// waits for slow subscriber, ping which doesn't fit the queue is handled by SlowPolicy. Channel of subscriber
+// is closed exactly once, either by Unsubscribe, by Close of its timer or by disconnect policy,
+// so unsubscribing twice or after close is safe
+type Broker struct {
+	queueSize int
+	policy    SlowPolicy
+	now       func() time.Time
+
+	mu     sync.Mutex
+	timers map[string]map[<-chan Ping]*subscriber
+	// dropped pings of running timers and of all timers
+	dropped      map[string]int64
+	droppedTotal int64
+}
+
+type subscriber struct {
+	c chan Ping
+	// every is interval between pings of subscriber and next is when the next one is due
+	every time.Duration
+
+	// mu serializes sending to c with closing it, it guards next as well
+	mu     sync.Mutex
+	next   time.Time
+	closed bool
+	// disconnected means c was closed by disconnect policy, subscriber is kept until it unsubscribes
+	disconnected bool
+}
+
+// NewBroker creates broker with queues of given size, not positive size means 8 pings
+// and empty policy means PolicyDropOldest
+func NewBroker(queueSize int, policy SlowPolicy) *Broker {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	if policy == PolicyCoalesce {
+		queueSize = 1
+	}
+	if policy == "" {
+		policy = PolicyDropOldest
+	}
+
+	return &Broker{
+		queueSize: queueSize,
+		policy:    policy,
+		now:       time.Now,
+		timers:    make(map[string]map[<-chan Ping]*subscriber),
+		dropped:   make(map[string]int64),
+	}
+}
+
+// Subscribe returns new channel which receives pings published to timer not more often than every,
+// the first ping is due after every since subscription. Not positive every means every published ping
+func (b *Broker) Subscribe(timerName string, every time.Duration) <-chan Ping {
+	s := &subscriber{
+		c:     make(chan Ping, b.queueSize),
+		every: every,
+		next:  b.now().Add(every),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timers[timerName] == nil {
+		b.timers[timerName] = make(map[<-chan Ping]*subscriber)
+	}
+	b.timers[timerName][s.c] = s
+
+	return s.c
+}
+
+// Unsubscribe removes channel from subscribers of timer and closes it
+//
+// It does nothing when channel is already unsubscribed or timer is closed.
+// ErrSlowConsumer returned when channel was closed by disconnect policy
+func (b *Broker) Unsubscribe(timerName string, c <-chan Ping) error {
+	b.mu.Lock()
+	s, ok := b.timers[timerName][c]
+	if ok {
+		delete(b.timers[timerName], c)
+		if len(b.timers[timerName]) == 0 {
+			delete(b.timers, timerName)
+		}
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if s.close() {
+		return fmt.Errorf("%w: %v", ErrSlowConsumer, "queue of timer updates overflowed")
+	}
+
+	return nil
+}
+
+// Publish sends ping to every subscriber of timer which is due and returns amount of subscribers which queued it
+//
+// It never blocks, pings which don't fit queues are handled by slow policy and counted as dropped
+func (b *Broker) Publish(timerName string, p Ping) int {
+	b.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(b.timers[timerName]))
+	for _, s := range b.timers[timerName] {
+		subscribers = append(subscribers, s)
+	}
+	b.mu.Unlock()
+
+	now := b.now()
+	sent := 0
+	var dropped int64
+	for _, s := range subscribers {
+		if !s.due(now) {
+			continue
+		}
+		queued, drop := s.send(p, b.policy)
+		if queued {
+			sent++
+		}
+		if drop {
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		b.mu.Lock()
+		// Timer may be closed meanwhile, then only total is counted
+		if _, ok := b.timers[timerName]; ok {
+			b.dropped[timerName] += dropped
+		}
+		b.droppedTotal += dropped
+		b.mu.Unlock()
+	}
+
+	return sent
+}
+
+// NextDue returns the earliest time when connected subscriber of timer is due,
+// false is returned when timer has no subscribers with interval
+func (b *Broker) NextDue(timerName string) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var next time.Time
+	for _, s := range b.timers[timerName] {
+		s.mu.Lock()
+		if !s.closed && s.every > 0 && (next.IsZero() || s.next.Before(next)) {
+			next = s.next
+		}
+		s.mu.Unlock()
+	}
+
+	return next, !next.IsZero()
+}
+
+// Close unsubscribes and closes all subscribers of timer
+func (b *Broker) Close(timerName string) {
+	b.mu.Lock()
+	subscribers := b.timers[timerName]
+	delete(b.timers, timerName)
+	delete(b.dropped, timerName)
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.close()
+	}
+}
+
+// Count returns amount of connected subscribers of timer
+func (b *Broker) Count(timerName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return countConnected(b.timers[timerName])
+}
+
+// Counts returns amount of connected subscribers of every timer which has any
+func (b *Broker) Counts() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	counts := make(map[string]int, len(b.timers))
+	for timerName, subscribers := range b.timers {
+		if n := countConnected(subscribers); n > 0 {
+			counts[timerName] = n
+		}
+	}
+
+	return counts
+}
+
+// Dropped returns amount of dropped pings of every running timer which has any, and of all timers
+// since broker creation
+func (b *Broker) Dropped() (map[string]int64, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dropped := make(map[string]int64, len(b.dropped))
+	for timerName, n := range b.dropped {
+		dropped[timerName] = n
+	}
+
+	return dropped, b.droppedTotal
+}
+
+func countConnected(subscribers map[<-chan Ping]*subscriber) int {
+	n := 0
+	for _, s := range subscribers {
+		s.mu.Lock()
+		if !s.closed {
+			n++
+		}
+		s.mu.Unlock()
+	}
+
+	return n
+}
+
+// due reports whether subscriber should get ping at now and schedules the next one,
+// missed pings are skipped, so late publisher doesn't cause burst of pings
+func (s *subscriber) due(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.every <= 0 {
+		return true
+	}
+	if now.Before(s.next) {
+		return false
+	}
+
+	s.next = s.next.Add(s.every)
+	if !now.Before(s.next) {
+		s.next = now.Add(s.every)
+	}
+	return true
+}
+
+// send queues ping without waiting, it reports whether ping was queued and whether any ping was dropped
+func (s *subscriber) send(p Ping, policy SlowPolicy) (queued bool, dropped bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, false
+	}
+
+	select {
+	case s.c <- p:
+		return true, false
+	default:
+	}
+
+	switch policy {
+	case PolicyDropNewest:
+		return false, true
+	case PolicyDisconnect:
+		s.closed = true
+		s.disconnected = true
+		close(s.c)
+		return false, true
+	default:
+		// Only publisher sends to c while holding mu, so there is room after the oldest ping is taken,
+		// unless subscriber has read it meanwhile, which leaves room as well
+		select {
+		case <-s.c:
+			dropped = true
+		default:
+		}
+		s.c <- p
+		return true, dropped
+	}
+}
+
+// close closes channel unless it is already closed, it reports whether channel was closed by disconnect policy
+func (s *subscriber) close() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.c)
+	}
+
+	return s.disconnected
+}
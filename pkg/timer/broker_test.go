@@ -0,0 +1,260 @@
+package timer
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	tc := []struct {
+		name string
+
+		unsubscribe func(b *Broker, c <-chan Ping)
+	}{
+		{
+			name: "once",
+			unsubscribe: func(b *Broker, c <-chan Ping) {
+				b.Unsubscribe("test", c)
+			},
+		},
+		{
+			name: "twice",
+			unsubscribe: func(b *Broker, c <-chan Ping) {
+				b.Unsubscribe("test", c)
+				b.Unsubscribe("test", c)
+			},
+		},
+		{
+			name: "after close",
+			unsubscribe: func(b *Broker, c <-chan Ping) {
+				b.Close("test")
+				b.Unsubscribe("test", c)
+			},
+		},
+		{
+			name: "close twice",
+			unsubscribe: func(b *Broker, c <-chan Ping) {
+				b.Close("test")
+				b.Close("test")
+			},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBroker(0, "")
+			c := b.Subscribe("test", 0)
+			other := b.Subscribe("other", 0)
+
+			require.NotPanics(t, func() {
+				tt.unsubscribe(b, c)
+			})
+			_, ok := <-c
+			assert.False(t, ok, "channel must be closed")
+			assert.Equal(t, 0, b.Count("test"))
+			assert.Equal(t, map[string]int{"other": 1}, b.Counts())
+
+			// Channel of other timer isn't affected
+			b.Unsubscribe("test", other)
+			assert.Equal(t, 1, b.Count("other"))
+		})
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	b := NewBroker(0, "")
+	first := b.Subscribe("test", 0)
+	second := b.Subscribe("test", 0)
+	assert.Equal(t, 2, b.Count("test"))
+
+	var wg sync.WaitGroup
+	for _, c := range []<-chan Ping{first, second} {
+		wg.Add(1)
+		go func(c <-chan Ping) {
+			defer wg.Done()
+			p := <-c
+			assert.Equal(t, Ping{TimerName: "test", SecondsLeft: 5}, p)
+		}(c)
+	}
+
+	assert.Equal(t, 2, b.Publish("test", Ping{TimerName: "test", SecondsLeft: 5}))
+	wg.Wait()
+	assert.Equal(t, 0, b.Publish("unknown", Ping{}))
+}
+
+func TestBroker_Frequency(t *testing.T) {
+	start := time.Now()
+	now := start
+	b := NewBroker(10, PolicyDropNewest)
+	b.now = func() time.Time { return now }
+
+	fast := b.Subscribe("test", time.Second)
+	slow := b.Subscribe("test", 3*time.Second)
+	next, ok := b.NextDue("test")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(time.Second), next)
+
+	// Publisher runs every half of second, subscribers get pings only when they are due
+	for i := 1; i <= 12; i++ {
+		now = start.Add(time.Duration(i) * 500 * time.Millisecond)
+		b.Publish("test", Ping{TimerName: "test", SecondsLeft: i})
+	}
+	assert.Len(t, fast, 6)
+	assert.Len(t, slow, 2)
+
+	next, ok = b.NextDue("test")
+	require.True(t, ok)
+	assert.Equal(t, start.Add(7*time.Second), next)
+
+	// Late publisher doesn't cause burst of missed pings
+	now = start.Add(time.Minute)
+	assert.Equal(t, 2, b.Publish("test", Ping{TimerName: "test"}))
+	assert.Equal(t, 0, b.Publish("test", Ping{TimerName: "test"}))
+	next, _ = b.NextDue("test")
+	assert.Equal(t, now.Add(time.Second), next)
+
+	b.Close("test")
+	_, ok = b.NextDue("test")
+	assert.False(t, ok)
+}
+
+func TestBroker_SlowPolicy(t *testing.T) {
+	tc := []struct {
+		name string
+
+		queueSize int
+		policy    SlowPolicy
+
+		expectedPings   []int
+		expectedDropped int64
+		wantErr         error
+	}{
+		{name: "drop oldest", queueSize: 2, policy: PolicyDropOldest, expectedPings: []int{4, 5}, expectedDropped: 3},
+		{name: "default is drop oldest", queueSize: 2, expectedPings: []int{4, 5}, expectedDropped: 3},
+		{name: "drop newest", queueSize: 2, policy: PolicyDropNewest, expectedPings: []int{1, 2}, expectedDropped: 3},
+		{name: "coalesce", queueSize: 3, policy: PolicyCoalesce, expectedPings: []int{5}, expectedDropped: 4},
+		{name: "disconnect", queueSize: 2, policy: PolicyDisconnect, expectedPings: []int{1, 2}, expectedDropped: 1, wantErr: ErrSlowConsumer},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBroker(tt.queueSize, tt.policy)
+			slow := b.Subscribe("test", 0)
+			fast := b.Subscribe("test", 0)
+
+			// Publisher never waits for slow subscriber, while fast one gets every ping
+			for i := 1; i <= 5; i++ {
+				b.Publish("test", Ping{TimerName: "test", SecondsLeft: i})
+				assert.Equal(t, i, (<-fast).SecondsLeft)
+			}
+
+			var pings []int
+			for len(slow) > 0 {
+				pings = append(pings, (<-slow).SecondsLeft)
+			}
+			assert.Equal(t, tt.expectedPings, pings)
+
+			dropped, total := b.Dropped()
+			assert.Equal(t, map[string]int64{"test": tt.expectedDropped}, dropped)
+			assert.Equal(t, tt.expectedDropped, total)
+
+			if tt.wantErr != nil {
+				_, ok := <-slow
+				assert.False(t, ok, "disconnected channel must be closed")
+				assert.Equal(t, 1, b.Count("test"))
+			}
+			err := b.Unsubscribe("test", slow)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, b.Unsubscribe("test", slow))
+
+			// Dropped pings of closed timer are kept only in total
+			b.Close("test")
+			dropped, total = b.Dropped()
+			assert.Empty(t, dropped)
+			assert.Equal(t, tt.expectedDropped, total)
+		})
+	}
+}
+
+func TestBroker_Concurrent(t *testing.T) {
+	const subscribers = 500
+
+	b := NewBroker(0, "")
+	stop := make(chan struct{})
+	publisherDone := make(chan struct{})
+	go func() {
+		defer close(publisherDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				b.Publish("test", Ping{TimerName: "test"})
+			}
+		}
+	}()
+
+	// Subscribers join, read a few pings and leave, some of them unsubscribe twice
+	var wg sync.WaitGroup
+	for i := range subscribers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := b.Subscribe("test", 0)
+			_ = b.Count("test")
+			for range i % 3 {
+				<-c
+			}
+			b.Unsubscribe("test", c)
+			if i%2 == 0 {
+				b.Unsubscribe("test", c)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	close(stop)
+	<-publisherDone
+	assert.Equal(t, 0, b.Count("test"))
+	assert.Empty(t, b.Counts())
+}
+
+func TestBroker_ConcurrentClose(t *testing.T) {
+	const subscribers = 300
+
+	b := NewBroker(0, "")
+	channels := make([]<-chan Ping, subscribers)
+	for i := range channels {
+		channels[i] = b.Subscribe("test", 0)
+	}
+
+	// Close of timer races with subscribers leaving on their own, every channel is closed exactly once
+	var wg sync.WaitGroup
+	for _, c := range channels {
+		wg.Add(1)
+		go func(c <-chan Ping) {
+			defer wg.Done()
+			b.Unsubscribe("test", c)
+		}(c)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.Publish("test", Ping{TimerName: "test"})
+		b.Close("test")
+	}()
+	wg.Wait()
+
+	for _, c := range channels {
+		for range c {
+		}
+	}
+	assert.Equal(t, 0, b.Count("test"))
+}
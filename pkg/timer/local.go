@@ -0,0 +1,143 @@
+package timer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetention = time.Hour
+)
+
+type deadline struct {
+	start time.Time
+	end   time.Time
+}
+
+// LocalEngine keeps timers in memory of the server process, so they work without network
+// and are visible only to clients of this server
+//
+// Deadlines are measured with monotonic clock, so they aren't affected by changes of wall clock.
+// Timed out timers are kept for retention and respond ErrTimedOut, after that they are forgotten
+type LocalEngine struct {
+	mu        sync.Mutex
+	timers    map[string]deadline
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewLocalEngine creates engine which forgets timed out timers after retention,
+// not positive retention means one hour
+func NewLocalEngine(retention time.Duration) *LocalEngine {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return &LocalEngine{
+		timers:    make(map[string]deadline),
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// CreateTimerContext starts timer with given name for given seconds, running timer with the same name is restarted
+func (e *LocalEngine) CreateTimerContext(ctx context.Context, name string, seconds int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if seconds <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidSeconds, seconds)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	// Forgotten timers are purged on creation, so map doesn't grow with timers nobody checks
+	for timerName, d := range e.timers {
+		if now.Sub(d.end) > e.retention {
+			delete(e.timers, timerName)
+		}
+	}
+	e.timers[name] = deadline{start: now, end: now.Add(time.Duration(seconds) * time.Second)}
+
+	return nil
+}
+
+// DeleteTimerContext forgets timer with given name
+//
+// ErrNotExists returned when timer doesn't exist
+func (e *LocalEngine) DeleteTimerContext(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.timers[name]; !ok {
+		return fmt.Errorf("%w: %v", ErrNotExists, "timer never been created")
+	}
+	delete(e.timers, name)
+
+	return nil
+}
+
+// ExtendTimerContext moves deadline of running timer by given seconds, its elapsed seconds are kept
+//
+// ErrTimedOut and ErrNotExists returned the same way as by CheckTimerContext
+func (e *LocalEngine) ExtendTimerContext(ctx context.Context, name string, seconds int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if seconds <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidSeconds, seconds)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	d, ok := e.timers[name]
+	if !ok || now.Sub(d.end) > e.retention {
+		return fmt.Errorf("%w: %v", ErrNotExists, "timer never been created")
+	}
+	if !now.Before(d.end) {
+		return fmt.Errorf("%w: %v", ErrTimedOut, "timer timed out")
+	}
+	d.end = d.end.Add(time.Duration(seconds) * time.Second)
+	e.timers[name] = d
+
+	return nil
+}
+
+// CheckTimerContext returns remaining and elapsed seconds of timer, remaining seconds are rounded up
+//
+// ErrTimedOut returned when timer exists but expired, ErrNotExists returned when timer has never been created
+// or was forgotten
+func (e *LocalEngine) CheckTimerContext(ctx context.Context, name string) (remain int, elapsed int, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	d, ok := e.timers[name]
+	e.mu.Unlock()
+
+	now := e.now()
+	if !ok || now.Sub(d.end) > e.retention {
+		err = fmt.Errorf("%w: %v", ErrNotExists, "timer never been created")
+		return
+	}
+	if !now.Before(d.end) {
+		err = fmt.Errorf("%w: %v", ErrTimedOut, "timer timed out")
+		return
+	}
+
+	remain = int(math.Ceil(d.end.Sub(now).Seconds()))
+	elapsed = int(now.Sub(d.start).Seconds())
+	return
+}
@@ -0,0 +1,167 @@
+package timer
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLocalEngine_TestCases(t *testing.T) {
+	start := time.Now()
+
+	tc := []struct {
+		name string
+
+		seconds int
+		after   time.Duration
+
+		expectedRemain  int
+		expectedElapsed int
+		wantErr         error
+	}{
+		{name: "just created", seconds: 10, after: 0, expectedRemain: 10, expectedElapsed: 0},
+		{name: "remaining is rounded up", seconds: 10, after: 2500 * time.Millisecond, expectedRemain: 8, expectedElapsed: 2},
+		{name: "last second", seconds: 10, after: 9999 * time.Millisecond, expectedRemain: 1, expectedElapsed: 9},
+		{name: "timed out at deadline", seconds: 10, after: 10 * time.Second, wantErr: ErrTimedOut},
+		{name: "timed out during retention", seconds: 10, after: 10*time.Second + time.Hour, wantErr: ErrTimedOut},
+		{name: "forgotten after retention", seconds: 10, after: 10*time.Second + time.Hour + time.Second, wantErr: ErrNotExists},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewLocalEngine(time.Hour)
+			e.now = func() time.Time { return start }
+			require.NoError(t, e.CreateTimerContext(context.Background(), "test", tt.seconds))
+
+			e.now = func() time.Time { return start.Add(tt.after) }
+			remain, elapsed, err := e.CheckTimerContext(context.Background(), "test")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRemain, remain)
+			assert.Equal(t, tt.expectedElapsed, elapsed)
+		})
+	}
+}
+
+func TestLocalEngine_NotExists(t *testing.T) {
+	e := NewLocalEngine(0)
+
+	_, _, err := e.CheckTimerContext(context.Background(), "test")
+	assert.ErrorIs(t, err, ErrNotExists)
+}
+
+func TestLocalEngine_Restart(t *testing.T) {
+	start := time.Now()
+	e := NewLocalEngine(time.Hour)
+	e.now = func() time.Time { return start }
+	require.NoError(t, e.CreateTimerContext(context.Background(), "test", 10))
+
+	// Timed out timer is started again with new duration
+	e.now = func() time.Time { return start.Add(time.Minute) }
+	require.NoError(t, e.CreateTimerContext(context.Background(), "test", 30))
+	remain, elapsed, err := e.CheckTimerContext(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, 30, remain)
+	assert.Equal(t, 0, elapsed)
+}
+
+func TestLocalEngine_PurgesForgotten(t *testing.T) {
+	start := time.Now()
+	e := NewLocalEngine(time.Minute)
+	e.now = func() time.Time { return start }
+	require.NoError(t, e.CreateTimerContext(context.Background(), "old", 1))
+
+	e.now = func() time.Time { return start.Add(time.Hour) }
+	require.NoError(t, e.CreateTimerContext(context.Background(), "new", 1))
+	assert.NotContains(t, e.timers, "old")
+	assert.Contains(t, e.timers, "new")
+}
+
+func TestLocalEngine_Errors(t *testing.T) {
+	e := NewLocalEngine(0)
+
+	err := e.CreateTimerContext(context.Background(), "test", 0)
+	assert.ErrorIs(t, err, ErrInvalidSeconds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, e.CreateTimerContext(ctx, "test", 10), context.Canceled)
+	_, _, err = e.CheckTimerContext(ctx, "test")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTimer_LocalEngine(t *testing.T) {
+	timer := NewTimer(NewLocalEngine(0))
+
+	ping, err := timer.Subscribe("test", 2, 1)
+	require.NoError(t, err)
+
+	// Timer is streamed with local engine until it times out and channel is closed
+	p, ok := <-ping
+	require.True(t, ok)
+	assert.Equal(t, "test", p.TimerName)
+	assert.Equal(t, 1, p.SecondsLeft)
+
+	select {
+	case _, ok = <-ping:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "channel must be closed after timer is timed out")
+	}
+}
+
+func TestLocalEngine_Delete(t *testing.T) {
+	e := NewLocalEngine(0)
+	require.NoError(t, e.CreateTimerContext(context.Background(), "test", 10))
+
+	require.NoError(t, e.DeleteTimerContext(context.Background(), "test"))
+	_, _, err := e.CheckTimerContext(context.Background(), "test")
+	assert.ErrorIs(t, err, ErrNotExists)
+
+	assert.ErrorIs(t, e.DeleteTimerContext(context.Background(), "test"), ErrNotExists)
+}
+
+func TestLocalEngine_Extend(t *testing.T) {
+	start := time.Now()
+
+	tc := []struct {
+		name    string
+		after   time.Duration
+		seconds int
+
+		expectedRemain  int
+		expectedElapsed int
+		wantErr         error
+	}{
+		{name: "running", after: 4 * time.Second, seconds: 5, expectedRemain: 11, expectedElapsed: 4},
+		{name: "timed out", after: 10 * time.Second, seconds: 5, wantErr: ErrTimedOut},
+		{name: "forgotten", after: 2 * time.Hour, seconds: 5, wantErr: ErrNotExists},
+		{name: "not positive seconds", after: 0, seconds: 0, wantErr: ErrInvalidSeconds},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewLocalEngine(time.Hour)
+			e.now = func() time.Time { return start }
+			require.NoError(t, e.CreateTimerContext(context.Background(), "test", 10))
+
+			e.now = func() time.Time { return start.Add(tt.after) }
+			err := e.ExtendTimerContext(context.Background(), "test", tt.seconds)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			remain, elapsed, err := e.CheckTimerContext(context.Background(), "test")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRemain, remain)
+			assert.Equal(t, tt.expectedElapsed, elapsed)
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package timer
 
 import (
-	"challenge/pkg/api/timercheck"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"sync"
 	"time"
 )
 
@@ -14,90 +15,293 @@ type Ping struct {
 	SecondsLeft int
 }
 
+var (
+	ErrTimedOut       = errors.New("timer timed out")
+	ErrNotExists      = errors.New("timer not exists")
+	ErrInvalidSeconds = errors.New("timer seconds must be positive")
+)
+
+// TimerBackend keeps countdowns of timers, e.g. timercheck.io API or LocalEngine
+//
+// Timed out timers are reported with ErrTimedOut and not existing ones with ErrNotExists.
+// Create restarts existing timer, Extend adds seconds to remaining time of running timer
+type TimerBackend interface {
+	CreateTimerContext(ctx context.Context, name string, seconds int) error
+	CheckTimerContext(ctx context.Context, name string) (remain int, elapsed int, err error)
+	DeleteTimerContext(ctx context.Context, name string) error
+	ExtendTimerContext(ctx context.Context, name string, seconds int) error
+}
+
+const (
+	defaultResync = 10 * time.Second
+	// minCheckInterval bounds backend checks when backend reports timer which is about to time out
+	minCheckInterval = time.Second
+)
+
 type Timer struct {
-	timerChecker timercheck.TimerCheck
-	su           *SubUnsub
+	backend TimerBackend
+	broker  *Broker
+	// queue of subscribers, see WithQueue
+	queueSize int
+	policy    SlowPolicy
+	// resync is interval between backend checks of running timer, see WithResync
+	resync time.Duration
+
+	// mu guards broadcasting only, backend is never called while it's held
+	mu           sync.Mutex
+	broadcasting map[string]*broadcast
+}
+
+// broadcast is state of timer subscriptions, its entry is kept while broadcast goroutine runs
+// or any subscription of the timer is in progress
+type broadcast struct {
+	// mu serializes subscriptions of timer with each other and with the end of its broadcast,
+	// backend is called while it's held, so subscriptions of other timers aren't blocked
+	mu      sync.Mutex
+	running bool
+	// deadline is the latest known deadline of timer
+	deadline time.Time
+	// wake wakes up running broadcast when subscriber joins
+	wake chan struct{}
+
+	// refs is amount of subscriptions in progress and running goroutine, it is guarded by Timer.mu
+	refs int
 }
 
-func NewTimer(timerChecker timercheck.TimerCheck) *Timer {
-	return &Timer{
-		timerChecker: timerChecker,
-		su:           NewSubUnsub(),
+// Option configures Timer on creation
+type Option func(*Timer)
+
+// WithQueue sets size of queue of every subscriber and policy of pings which don't fit the queue,
+// by default queue holds 8 pings and the oldest ones are dropped
+func WithQueue(size int, policy SlowPolicy) Option {
+	return func(t *Timer) {
+		t.queueSize = size
+		t.policy = policy
 	}
 }
 
+// WithResync sets how often running timer is checked on backend, e.g. to notice extended or deleted timer,
+// remaining seconds between checks are computed from deadline. Default interval is 10 seconds
+func WithResync(interval time.Duration) Option {
+	return func(t *Timer) {
+		if interval > 0 {
+			t.resync = interval
+		}
+	}
+}
+
+func NewTimer(backend TimerBackend, opts ...Option) *Timer {
+	t := &Timer{
+		backend:      backend,
+		resync:       defaultResync,
+		broadcasting: make(map[string]*broadcast),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.broker = NewBroker(t.queueSize, t.policy)
+
+	return t
+}
+
 // Subscribe subscribes to timer updates on returned channel
 //
-// If some timer currently running, it will return streaming channel bound to frequency
-// that was set when timer was firstly created
+// Every subscriber gets updates with its own frequency in seconds, not positive frequency means every second.
+// Remaining seconds are computed from deadline of timer shared by all subscribers, so backend is checked
+// by single broadcast goroutine regardless of amount of subscribers
 //
 //	If timer not exists or timed out, it will create new broadcast goroutine and subscribe new channel to this goroutine
 //
 // When timer expires, all subscribed channels will be automatically unsubscribed(closed)
-func (t *Timer) Subscribe(timerName string, timerSeconds int, freq int) (chan Ping, error) {
+func (t *Timer) Subscribe(timerName string, timerSeconds int, freq int) (<-chan Ping, error) {
+	return t.SubscribeContext(context.Background(), timerName, timerSeconds, freq)
+}
 
-	_, _, err := t.timerChecker.CheckTimer(timerName)
-	if err == nil {
-		// If timer already running, subscribe to it
+// SubscribeContext is Subscribe which stops checking and creating timer when ctx is done
+//
+// Broadcasting goroutine is shared by all subscribers, so it's not bound to ctx.
+// It is safe for concurrent use, subscriptions of the same timer are serialized, so timer is created only once,
+// while subscriptions of different timers don't wait for each other
+func (t *Timer) SubscribeContext(ctx context.Context, timerName string, timerSeconds int, freq int) (<-chan Ping, error) {
+	every := time.Duration(max(freq, 1)) * time.Second
+
+	b := t.acquire(timerName)
+	defer t.release(timerName, b)
+
+	// Running broadcast is joined without backend call until its deadline passes,
+	// after that timer is checked again, it may have timed out right before subscription
+	if !b.running || !time.Now().Before(b.deadline) {
+		// Timer may be running on backend without broadcast, e.g. when it was created by another server
+		remain, _, err := t.backend.CheckTimerContext(ctx, timerName)
+		if err != nil {
+			if !errors.Is(err, ErrTimedOut) && !errors.Is(err, ErrNotExists) {
+				log.Println("error when checking timer: " + timerName)
+				return nil, fmt.Errorf("%w: %v", err, "timer creation failed")
+			}
+
+			// Create timer
+			if err := t.backend.CreateTimerContext(ctx, timerName, timerSeconds); err != nil {
+				return nil, fmt.Errorf("%w: %v", err, "timer creation failed")
+			}
+			remain = timerSeconds
+		}
+
+		// Deadline is set before subscription, so remaining seconds of the first ping aren't rounded up
+		b.deadline = time.Now().Add(time.Duration(remain) * time.Second)
+	}
+
+	c := t.broker.Subscribe(timerName, every)
+	if b.running {
+		// If timer already running, let broadcast schedule new subscriber and pick up new deadline
 		log.Println("timer already running with name: " + timerName)
-		log.Println("update frequency binds may be different")
-		c := make(chan Ping)
-		t.su.Sub(timerName, c)
-		return c, nil
-	} else {
-		if !errors.Is(err, timercheck.ErrTimedOut) && !errors.Is(err, timercheck.ErrNotExists) {
-			log.Println("error when checking timer: " + timerName)
-			return nil, fmt.Errorf("%w: %v", err, "timer creation failed")
+		select {
+		case b.wake <- struct{}{}:
+		default:
 		}
+		return c, nil
 	}
 
-	// Create timer and subscribe new channel
-	if err := t.timerChecker.CreateTimer(timerName, timerSeconds); err != nil {
-		return nil, fmt.Errorf("%w: %v", err, "timer creation failed")
+	// Create broadcasting goroutine, which holds its own reference to the entry
+	b.running = true
+	t.mu.Lock()
+	b.refs++
+	t.mu.Unlock()
+	go t.broadcast(timerName, b)
+
+	return c, nil
+}
+
+// acquire returns locked entry of timer, it is created if timer has none
+func (t *Timer) acquire(timerName string) *broadcast {
+	t.mu.Lock()
+	b, ok := t.broadcasting[timerName]
+	if !ok {
+		b = &broadcast{wake: make(chan struct{}, 1)}
+		t.broadcasting[timerName] = b
 	}
-	c := make(chan Ping)
-	t.su.Sub(timerName, c)
+	b.refs++
+	t.mu.Unlock()
 
-	// Create broadcasting goroutine
-	ticker := time.NewTicker(time.Duration(freq) * time.Second)
-	go func() {
-		defer func() {
-			t.su.UnsubAll(timerName)
-			ticker.Stop()
-			log.Println("returning from Subscribe timer goroutine")
-		}()
+	b.mu.Lock()
+	return b
+}
 
-		for {
-			select {
-			case <-ticker.C:
-				r, _, err := t.timerChecker.CheckTimer(timerName)
-				if err != nil {
-					if errors.Is(err, timercheck.ErrTimedOut) {
-						return
-					}
+// release unlocks entry of timer and drops reference to it
+func (t *Timer) release(timerName string, b *broadcast) {
+	b.mu.Unlock()
+	t.unref(timerName, b)
+}
+
+// unref drops reference to entry of timer, entry is deleted when nobody references it
+func (t *Timer) unref(timerName string, b *broadcast) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b.refs--
+	if b.refs == 0 {
+		delete(t.broadcasting, timerName)
+	}
+}
+
+// broadcast publishes remaining seconds of timer to subscribers when they are due, until timer times out or fails,
+// then all subscribers are closed
+//
+// Remaining seconds are computed from deadline, which is updated from backend every resync and when it passes.
+// Backend is checked while subscriptions of timer are locked out, so nobody joins broadcast between the check
+// which decides to stop it and closing of subscribers
+func (t *Timer) broadcast(timerName string, b *broadcast) {
+	wait := time.NewTimer(0)
+	defer func() {
+		wait.Stop()
+		log.Println("returning from Subscribe timer goroutine")
+	}()
+
+	checked := time.Now()
+	for {
+		select {
+		case <-wait.C:
+		case <-b.wake:
+		}
+
+		// Subscriber may have moved deadline, e.g. by creating timer which timed out again
+		b.mu.Lock()
+		now := time.Now()
+		deadline := b.deadline
+		if !now.Before(t.nextCheck(checked, deadline)) {
+			r, _, err := t.backend.CheckTimerContext(context.Background(), timerName)
+			if err != nil {
+				if !errors.Is(err, ErrTimedOut) {
 					log.Println("error when checking timer: ", err)
-					return
-				}
-				p := Ping{
-					TimerName:   timerName,
-					SecondsLeft: r,
-				}
-				log.Printf("streaming to %d subscribed channels. timer name: %s\n", len(t.su.timers[timerName]), timerName)
-				for c, _ := range t.su.timers[timerName] {
-					c <- p
 				}
+				b.running = false
+				t.broker.Close(timerName)
+				t.release(timerName, b)
+				return
 			}
+			checked = now
+			deadline = now.Add(time.Duration(r) * time.Second)
+			b.deadline = deadline
 		}
+		b.mu.Unlock()
 
-	}()
+		p := Ping{
+			TimerName:   timerName,
+			SecondsLeft: max(int(math.Ceil(deadline.Sub(now).Seconds())), 0),
+		}
+		if sent := t.broker.Publish(timerName, p); sent > 0 {
+			log.Printf("queued to %d subscribed channels. timer name: %s\n", sent, timerName)
+		}
 
-	return c, nil
+		next := t.nextCheck(checked, deadline)
+		if due, ok := t.broker.NextDue(timerName); ok && due.Before(next) {
+			next = due
+		}
+		// Timer may have fired while goroutine was woken up by subscriber, it is drained before reset
+		if !wait.Stop() {
+			select {
+			case <-wait.C:
+			default:
+			}
+		}
+		wait.Reset(time.Until(next))
+	}
+}
+
+// nextCheck returns when timer should be checked on backend: after resync or at deadline, whichever is earlier
+func (t *Timer) nextCheck(checked time.Time, deadline time.Time) time.Time {
+	next := checked.Add(t.resync)
+	if deadline.Before(next) {
+		next = deadline
+	}
+	if earliest := checked.Add(minCheckInterval); next.Before(earliest) {
+		next = earliest
+	}
+
+	return next
+}
+
+// Unsubscribe deletes given channel bound to given timer name from broadcast system and closes it
+//
+// It is safe to unsubscribe channel more than once and after timer expired.
+// ErrSlowConsumer returned when channel was closed because it didn't keep up with updates
+func (t *Timer) Unsubscribe(timerName string, c <-chan Ping) error {
+	return t.broker.Unsubscribe(timerName, c)
 }
 
-// Unsubscribe simply deletes given channel bound to given timer name
-// from broadcast system
-func (t *Timer) Unsubscribe(timerName string, c chan Ping) {
-	t.su.Unsub(timerName, c)
+// Subscribers returns amount of channels subscribed to timer
+func (t *Timer) Subscribers(timerName string) int {
+	return t.broker.Count(timerName)
+}
+
+// AllSubscribers returns amount of subscribed channels of every timer which has any
+func (t *Timer) AllSubscribers() map[string]int {
+	return t.broker.Counts()
+}
+
+// Dropped returns amount of updates dropped for slow subscribers of every running timer which has any,
+// and of all timers
+func (t *Timer) Dropped() (map[string]int64, int64) {
+	return t.broker.Dropped()
 }
 
 // StartOrSubscribe creating new streaming channel which gets timer updates with given frequency
@@ -108,12 +312,17 @@ func (t *Timer) Unsubscribe(timerName string, c chan Ping) {
 //
 // NOTE: Subscribe recommended to use instead, because it reduces API calls due to broadcasting system
 func (t *Timer) StartOrSubscribe(timerName string, timerSeconds int, freq int) (<-chan Ping, context.CancelFunc, error) {
+	return t.StartOrSubscribeContext(context.Background(), timerName, timerSeconds, freq)
+}
+
+// StartOrSubscribeContext is StartOrSubscribe which streaming is also interrupted when parent ctx is done
+func (t *Timer) StartOrSubscribeContext(parent context.Context, timerName string, timerSeconds int, freq int) (<-chan Ping, context.CancelFunc, error) {
 
-	_, _, err := t.timerChecker.CheckTimer(timerName)
+	_, _, err := t.backend.CheckTimerContext(parent, timerName)
 	if err != nil {
-		if errors.Is(err, timercheck.ErrTimedOut) || errors.Is(err, timercheck.ErrNotExists) {
+		if errors.Is(err, ErrTimedOut) || errors.Is(err, ErrNotExists) {
 			log.Println("timer doesn't exist, creating new timer with name: " + timerName)
-			if err := t.timerChecker.CreateTimer(timerName, timerSeconds); err != nil {
+			if err := t.backend.CreateTimerContext(parent, timerName, timerSeconds); err != nil {
 				return nil, nil, fmt.Errorf("%w: %v", err, "timer creation failed")
 			}
 		} else {
@@ -122,7 +331,7 @@ func (t *Timer) StartOrSubscribe(timerName string, timerSeconds int, freq int) (
 	}
 
 	ticker := time.NewTicker(time.Duration(freq) * time.Second)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 	ping := make(chan Ping)
 	go func() {
 		defer func() {
@@ -136,9 +345,9 @@ func (t *Timer) StartOrSubscribe(timerName string, timerSeconds int, freq int) (
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				r, _, err := t.timerChecker.CheckTimer(timerName)
+				r, _, err := t.backend.CheckTimerContext(ctx, timerName)
 				if err != nil {
-					if errors.Is(err, timercheck.ErrTimedOut) {
+					if errors.Is(err, ErrTimedOut) {
 						return
 					}
 					log.Println("error when checking timer: ", err)
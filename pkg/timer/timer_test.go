@@ -0,0 +1,293 @@
+package timer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// backendMock is LocalEngine which counts calls and can fail checks
+type backendMock struct {
+	*LocalEngine
+
+	mu       sync.Mutex
+	checkErr error
+	creates  int
+	checks   int
+	// onCreate is called before timer is created, e.g. to make backend slow
+	onCreate func(name string)
+	// onCheck is called with result of every check
+	onCheck func(name string, err error)
+}
+
+func newBackendMock() *backendMock {
+	return &backendMock{LocalEngine: NewLocalEngine(0)}
+}
+
+func (b *backendMock) CreateTimerContext(ctx context.Context, name string, seconds int) error {
+	b.mu.Lock()
+	b.creates++
+	onCreate := b.onCreate
+	b.mu.Unlock()
+	if onCreate != nil {
+		onCreate(name)
+	}
+	return b.LocalEngine.CreateTimerContext(ctx, name, seconds)
+}
+
+func (b *backendMock) CheckTimerContext(ctx context.Context, name string) (int, int, error) {
+	b.mu.Lock()
+	b.checks++
+	checkErr := b.checkErr
+	onCheck := b.onCheck
+	b.mu.Unlock()
+	if checkErr != nil {
+		return 0, 0, checkErr
+	}
+	remain, elapsed, err := b.LocalEngine.CheckTimerContext(ctx, name)
+	if onCheck != nil {
+		onCheck(name, err)
+	}
+	return remain, elapsed, err
+}
+
+func (b *backendMock) calls() (creates int, checks int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.creates, b.checks
+}
+
+// receive collects pings of every channel until all of them are closed
+func receive(t *testing.T, channels ...<-chan Ping) [][]int {
+	var wg sync.WaitGroup
+	received := make([][]int, len(channels))
+	for i, c := range channels {
+		wg.Add(1)
+		go func(i int, c <-chan Ping) {
+			defer wg.Done()
+			for p := range c {
+				received[i] = append(received[i], p.SecondsLeft)
+			}
+		}(i, c)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "channels must be closed after timer is timed out")
+	}
+
+	return received
+}
+
+func TestSubscribe_TestCases(t *testing.T) {
+	tc := []struct {
+		name string
+
+		existing time.Duration
+		checkErr error
+
+		wantCreates int
+		wantErr     bool
+	}{
+		{name: "not exists, created", wantCreates: 1},
+		{name: "timed out, created again", existing: -time.Second, wantCreates: 1},
+		{name: "running, subscribed", existing: 5 * time.Second, wantCreates: 0},
+		{name: "backend error", checkErr: errors.New("unavailable"), wantErr: true},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newBackendMock()
+			if tt.existing != 0 {
+				// Timer is created in the past, so it has given time left now
+				start := time.Now().Add(tt.existing - 10*time.Second)
+				backend.LocalEngine.now = func() time.Time { return start }
+				require.NoError(t, backend.LocalEngine.CreateTimerContext(context.Background(), "test", 10))
+				backend.LocalEngine.now = time.Now
+			}
+			backend.checkErr = tt.checkErr
+
+			_, err := NewTimer(backend).Subscribe("test", 10, 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			creates, _ := backend.calls()
+			assert.Equal(t, tt.wantCreates, creates)
+		})
+	}
+}
+
+func TestSubscribe_Broadcast(t *testing.T) {
+	backend := newBackendMock()
+	timer := NewTimer(backend)
+
+	first, err := timer.Subscribe("test", 2, 1)
+	require.NoError(t, err)
+	second, err := timer.Subscribe("test", 2, 1)
+	require.NoError(t, err)
+
+	// Both subscribers get the same pings until timer times out and their channels are closed
+	received := receive(t, first, second)
+
+	creates, _ := backend.calls()
+	assert.Equal(t, 1, creates)
+	for i := range received {
+		assert.Equal(t, []int{1}, received[i], fmt.Sprintf("subscriber %d", i))
+	}
+}
+
+func TestSubscribe_Frequency(t *testing.T) {
+	const subscribers = 100
+
+	backend := newBackendMock()
+	timer := NewTimer(backend)
+
+	// Every subscriber gets pings with its own frequency, no matter which one created timer
+	slow, err := timer.Subscribe("test", 3, 2)
+	require.NoError(t, err)
+	channels := []<-chan Ping{slow}
+	for range subscribers {
+		fast, err := timer.Subscribe("test", 3, 1)
+		require.NoError(t, err)
+		channels = append(channels, fast)
+	}
+	received := receive(t, channels...)
+
+	assert.Equal(t, []int{1}, received[0])
+	for i := 1; i < len(received); i++ {
+		assert.Equal(t, []int{2, 1}, received[i], fmt.Sprintf("subscriber %d", i))
+	}
+
+	// Backend is checked on subscription and when deadline passes, not per subscriber
+	_, checks := backend.calls()
+	assert.Equal(t, 2, checks)
+}
+
+func TestSubscribe_Resync(t *testing.T) {
+	backend := newBackendMock()
+	timer := NewTimer(backend, WithResync(time.Second))
+
+	c, err := timer.Subscribe("test", 2, 1)
+	require.NoError(t, err)
+
+	// Deadline is moved on the next check of backend, backend reports whole seconds,
+	// so only amount of pings is checked
+	require.NoError(t, backend.ExtendTimerContext(context.Background(), "test", 2))
+	received := receive(t, c)
+	assert.GreaterOrEqual(t, len(received[0]), 3)
+}
+
+func TestSubscribe_Concurrent(t *testing.T) {
+	const subscribers = 200
+
+	backend := newBackendMock()
+	timer := NewTimer(backend)
+
+	// Subscribers join and leave while timer is broadcasting, timer is created only once
+	var wg sync.WaitGroup
+	for i := range subscribers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := timer.Subscribe("test", 60, 1)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if i%4 == 0 {
+				<-c
+			}
+			timer.Unsubscribe("test", c)
+			timer.Unsubscribe("test", c)
+		}(i)
+	}
+	wg.Wait()
+
+	creates, _ := backend.calls()
+	assert.Equal(t, 1, creates)
+	assert.Equal(t, 0, timer.Subscribers("test"))
+}
+
+func TestSubscribe_AtTimeout(t *testing.T) {
+	backend := newBackendMock()
+	timer := NewTimer(backend)
+
+	// Subscriber comes right when broadcast has found out that timer timed out,
+	// it must get new timer instead of being closed together with broadcast
+	var once sync.Once
+	late := make(chan (<-chan Ping), 1)
+	backend.onCheck = func(_ string, err error) {
+		if !errors.Is(err, ErrTimedOut) {
+			return
+		}
+		once.Do(func() {
+			go func() {
+				c, err := timer.Subscribe("test", 2, 1)
+				assert.NoError(t, err)
+				late <- c
+			}()
+			// Subscription gets into the gap between the check and the end of broadcast
+			time.Sleep(50 * time.Millisecond)
+		})
+	}
+
+	first, err := timer.Subscribe("test", 1, 1)
+	require.NoError(t, err)
+
+	receive(t, first)
+
+	// Updates depend on scheduling of ticks, late subscriber must get any of them before new timer times out
+	received := receive(t, <-late)
+	assert.NotEmpty(t, received[0])
+	for _, secondsLeft := range received[0] {
+		assert.LessOrEqual(t, secondsLeft, 2)
+	}
+	creates, _ := backend.calls()
+	assert.Equal(t, 2, creates)
+}
+
+func TestSubscribe_SlowBackend(t *testing.T) {
+	backend := newBackendMock()
+	release := make(chan struct{})
+	backend.onCreate = func(name string) {
+		if name == "slow" {
+			<-release
+		}
+	}
+	timer := NewTimer(backend)
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := timer.Subscribe("slow", 60, 1)
+		slowDone <- err
+	}()
+
+	// Slow creation of one timer doesn't block subscriptions of other timers
+	fastDone := make(chan error, 1)
+	go func() {
+		_, err := timer.Subscribe("fast", 60, 1)
+		fastDone <- err
+	}()
+	select {
+	case err := <-fastDone:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "subscription of other timer must not wait for slow backend")
+	}
+
+	close(release)
+	assert.NoError(t, <-slowDone)
+	assert.Equal(t, 1, timer.Subscribers("slow"))
+}
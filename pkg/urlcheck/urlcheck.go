@@ -0,0 +1,187 @@
+// Package urlcheck validates urls before shortening
+package urlcheck
+
+import (
+	"challenge/pkg/urlnorm"
+	"errors"
+	"fmt"
+	"golang.org/x/net/idna"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidUrl = errors.New("invalid url")
+)
+
+// sharedAddressSpace is used by carrier-grade NAT(RFC 6598), it isn't reachable from the internet
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+var hostnameRegexp = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type Rules struct {
+	// Schemes is allowlist of url schemes, http and https are allowed if empty
+	Schemes []string
+	// MaxLength of url, not positive value means no limit
+	MaxLength int
+	// RejectPrivate rejects localhost and ip literals which aren't public, see IsPrivate.
+	// Host names aren't resolved, so names pointing to internal addresses pass, connections to them
+	// must be refused by client, see urlpolicy.PublicClient
+	RejectPrivate bool
+}
+
+type Validator struct {
+	schemes       map[string]bool
+	maxLength     int
+	rejectPrivate bool
+}
+
+func NewValidator(rules Rules) *Validator {
+	schemes := rules.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+
+	v := &Validator{
+		schemes:       make(map[string]bool, len(schemes)),
+		maxLength:     rules.MaxLength,
+		rejectPrivate: rules.RejectPrivate,
+	}
+	for _, s := range schemes {
+		v.schemes[strings.ToLower(s)] = true
+	}
+
+	return v
+}
+
+// Validate checks given url against validator rules and returns it without surrounding spaces,
+// url is otherwise kept as sent, its canonical form is only for comparison, see urlnorm.Normalize
+//
+// ErrInvalidUrl returned with the reason of rejection
+func (v *Validator) Validate(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, "url is empty")
+	}
+	if v.maxLength > 0 && len(raw) > v.maxLength {
+		return "", fmt.Errorf("%w: url is longer than %d characters", ErrInvalidUrl, v.maxLength)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, "url can't be parsed")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, "url must be absolute")
+	}
+	if !v.schemes[strings.ToLower(u.Scheme)] {
+		return "", fmt.Errorf("%w: scheme %s is not allowed", ErrInvalidUrl, u.Scheme)
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, "credentials in url are not allowed")
+	}
+	if err := v.checkHost(strings.ToLower(u.Hostname())); err != nil {
+		return "", err
+	}
+
+	// Url which can't be compared with others is rejected, so it can't bypass deduplication
+	if _, err := urlnorm.Normalize(raw); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, err)
+	}
+
+	return raw, nil
+}
+
+func (v *Validator) checkHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("%w: %v", ErrInvalidUrl, "host is empty")
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		// Resolvers and browsers accept ipv4 in short, numeric, hex and octal forms as well, e.g. 127.1 or 0x7f000001
+		addr, err = parseIPv4(strings.TrimSuffix(host, "."))
+	}
+	if err == nil {
+		if v.rejectPrivate && IsPrivate(addr) {
+			return fmt.Errorf("%w: host %s is not public", ErrInvalidUrl, host)
+		}
+		return nil
+	}
+	if !errors.Is(err, errNotIPv4) {
+		return fmt.Errorf("%w: host %s is not valid", ErrInvalidUrl, host)
+	}
+
+	// Internationalized domain names are checked in their ascii form
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil || !hostnameRegexp.MatchString(strings.TrimSuffix(ascii, ".")) {
+		return fmt.Errorf("%w: host %s is not valid", ErrInvalidUrl, host)
+	}
+	name := strings.TrimSuffix(host, ".")
+	if v.rejectPrivate && (name == "localhost" || strings.HasSuffix(name, ".localhost")) {
+		return fmt.Errorf("%w: host %s is not public", ErrInvalidUrl, host)
+	}
+
+	return nil
+}
+
+// errNotIPv4 is returned by parseIPv4 for hosts which are domain names
+var errNotIPv4 = errors.New("host is not ipv4 address")
+
+// parseIPv4 parses ipv4 address the way inet_aton does: 1 to 4 parts, each decimal, hex(0x) or octal(0),
+// the last part fills all remaining bytes
+//
+// Host with numeric last label is address, so errNotIPv4 is returned only when the last label isn't numeric,
+// other errors mean the address is malformed
+func parseIPv4(host string) (netip.Addr, error) {
+	parts := strings.Split(host, ".")
+	if _, err := parseIPv4Part(parts[len(parts)-1]); err != nil {
+		return netip.Addr{}, errNotIPv4
+	}
+	if len(parts) > 4 {
+		return netip.Addr{}, fmt.Errorf("ipv4 address %s has more than 4 parts", host)
+	}
+
+	var ip uint64
+	for _, part := range parts[:len(parts)-1] {
+		n, err := parseIPv4Part(part)
+		if err != nil || n > 0xff {
+			return netip.Addr{}, fmt.Errorf("ipv4 address %s is not valid", host)
+		}
+		ip = ip<<8 | n
+	}
+	// The last part fills the rest, e.g. 127.1 is 127.0.0.1
+	rest := 8 * (5 - len(parts))
+	n, _ := parseIPv4Part(parts[len(parts)-1])
+	if n >= 1<<rest {
+		return netip.Addr{}, fmt.Errorf("ipv4 address %s is out of range", host)
+	}
+	ip = ip<<rest | n
+
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), nil
+}
+
+func parseIPv4Part(part string) (uint64, error) {
+	base := 10
+	switch {
+	case strings.HasPrefix(part, "0x"):
+		base, part = 16, part[2:]
+		if part == "" {
+			return 0, nil
+		}
+	case len(part) > 1 && part[0] == '0':
+		base, part = 8, part[1:]
+	}
+
+	return strconv.ParseUint(part, base, 32)
+}
+
+// IsPrivate reports whether address is loopback, private, carrier-grade NAT, link-local, multicast or unspecified
+func IsPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || sharedAddressSpace.Contains(addr) || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsUnspecified() || addr.IsMulticast()
+}
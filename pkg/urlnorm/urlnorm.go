@@ -0,0 +1,77 @@
+// Package urlnorm provides canonical form of urls, so equal urls written differently can be compared
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/idna"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidUrl = errors.New("invalid url")
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns canonical form of the given absolute url
+//
+// Scheme and host are lowercased, internationalized host is converted to punycode, default port is removed,
+// empty path becomes "/" and query parameters are sorted by key. Parameters are sorted as written, they aren't
+// decoded, so valueless, badly escaped and ;-separated parameters are kept. Fragment is kept as well,
+// so urls which lead to different places never get the same canonical form
+//
+// ErrInvalidUrl returned when url can't be parsed or it's not absolute
+func Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("%w: %v", ErrInvalidUrl, "url must be absolute")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if ascii, err := idna.Lookup.ToASCII(u.Hostname()); err == nil && ascii != u.Hostname() {
+		u.Host = strings.Replace(u.Host, u.Hostname(), ascii, 1)
+	}
+	if host, port, err := net.SplitHostPort(u.Host); err == nil && defaultPorts[u.Scheme] == port {
+		u.Host = host
+		if strings.Contains(host, ":") {
+			// IPv6 literal must keep brackets
+			u.Host = "[" + host + "]"
+		}
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = sortQuery(u.RawQuery)
+	}
+
+	return u.String(), nil
+}
+
+// sortQuery sorts parameters of raw query by key, order of parameters with the same key is kept
+func sortQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	sort.SliceStable(params, func(i, j int) bool {
+		return queryKey(params[i]) < queryKey(params[j])
+	})
+
+	return strings.Join(params, "&")
+}
+
+func queryKey(param string) string {
+	key, _, _ := strings.Cut(param, "=")
+	return key
+}
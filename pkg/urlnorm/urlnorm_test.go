@@ -0,0 +1,98 @@
+package urlnorm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalize_TestCases(t *testing.T) {
+	tc := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "already canonical",
+			raw:  "https://www.google.com/",
+			want: "https://www.google.com/",
+		},
+		{
+			name: "case, empty path and spaces",
+			raw:  "  HTTPS://WWW.Google.COM ",
+			want: "https://www.google.com/",
+		},
+		{
+			name: "default port",
+			raw:  "http://example.com:80/path",
+			want: "http://example.com/path",
+		},
+		{
+			name: "fragment kept",
+			raw:  "http://example.com/path?b=1&a=2#Section",
+			want: "http://example.com/path?a=2&b=1#Section",
+		},
+		{
+			name: "non default port kept",
+			raw:  "https://example.com:8443/path",
+			want: "https://example.com:8443/path",
+		},
+		{
+			name: "ipv6 default port",
+			raw:  "https://[::1]:443/",
+			want: "https://[::1]/",
+		},
+		{
+			name: "internationalized host",
+			raw:  "https://Bücher.de:443/",
+			want: "https://xn--bcher-kva.de/",
+		},
+		{
+			name: "sorted query",
+			raw:  "https://example.com/search?q=go&a=1&a=0",
+			want: "https://example.com/search?a=1&a=0&q=go",
+		},
+		{
+			name: "semicolon separated query kept",
+			raw:  "https://example.com/?a=1;b=2",
+			want: "https://example.com/?a=1;b=2",
+		},
+		{
+			name: "valueless parameter kept",
+			raw:  "https://example.com/?z=1&flag",
+			want: "https://example.com/?flag&z=1",
+		},
+		{
+			name: "badly escaped parameter kept",
+			raw:  "https://example.com/?x=%zz&a=%20",
+			want: "https://example.com/?a=%20&x=%zz",
+		},
+		{
+			name:    "relative url",
+			raw:     "/path",
+			wantErr: true,
+		},
+		{
+			name:    "not a url",
+			raw:     "not a url",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			raw:     "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidUrl)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package urlpolicy
+
+import (
+	"challenge/pkg/urlcheck"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrPrivateAddress = errors.New("destination address is not public")
+)
+
+// PublicClient creates http client for reachability check, which refuses to connect to
+// addresses which aren't public(see urlcheck.IsPrivate), so the check can't be used to probe internal hosts
+//
+// Addresses are checked after name resolution, so it covers redirects and hosts resolving to internal addresses
+func PublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || urlcheck.IsPrivate(addr) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxy would connect to destination on behalf of the client, bypassing the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
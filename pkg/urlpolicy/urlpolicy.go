@@ -0,0 +1,270 @@
+// Package urlpolicy decides whether destination of long url is safe to be shortened
+// Rules are loaded from files and can be reloaded at runtime without restart
+package urlpolicy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/idna"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrRejected = errors.New("url is rejected by policy")
+	ErrInternal = errors.New("internal error")
+)
+
+const (
+	RuleBlocklist    = "blocklist"
+	RuleAllowlist    = "allowlist"
+	RulePattern      = "pattern"
+	RuleReachability = "reachability"
+	RuleHost         = "host"
+)
+
+// RuleError is returned for url rejected by policy, Rule names the rule that matched
+type RuleError struct {
+	Rule   string
+	Reason string
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("%v: rule %s: %s", ErrRejected, e.Rule, e.Reason)
+}
+
+func (e *RuleError) Unwrap() error {
+	return ErrRejected
+}
+
+// Files are paths of rule files, empty path disables its rules
+//
+// Blocklist and Allowlist contain domain per line, domain matches its subdomains as well.
+// Patterns contain regular expression per line, which is matched against the whole url.
+// Empty lines and lines starting with # are ignored
+type Files struct {
+	Blocklist string
+	Allowlist string
+	Patterns  string
+}
+
+type rules struct {
+	block    []string
+	allow    []string
+	patterns []*regexp.Regexp
+}
+
+// Policy checks urls against block and allow lists, patterns and optionally reachability of destination
+type Policy struct {
+	files Files
+	rules atomic.Pointer[rules]
+	// reload is serialized, so concurrent reloads don't overwrite newer rules with older ones
+	reloadMu sync.Mutex
+
+	client *http.Client
+}
+
+// Option configures Policy on creation
+type Option func(*Policy)
+
+// WithReachability enables check that destination responds to HEAD or GET request
+// with not error status, requests are sent with given client
+func WithReachability(client *http.Client) Option {
+	return func(p *Policy) {
+		p.client = client
+	}
+}
+
+// NewPolicy creates policy and loads its rules from files
+func NewPolicy(files Files, opts ...Option) (*Policy, error) {
+	p := &Policy{files: files}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Reload reads rule files again, previous rules are kept if any file can't be loaded
+func (p *Policy) Reload() error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	block, err := readDomains(p.files.Blocklist)
+	if err != nil {
+		return err
+	}
+	allow, err := readDomains(p.files.Allowlist)
+	if err != nil {
+		return err
+	}
+	patterns, err := readPatterns(p.files.Patterns)
+	if err != nil {
+		return err
+	}
+
+	p.rules.Store(&rules{block: block, allow: allow, patterns: patterns})
+	return nil
+}
+
+// Check checks url against policy rules
+func (p *Policy) Check(raw string) error {
+	return p.CheckContext(context.Background(), raw)
+}
+
+// CheckContext is Check which passes ctx to reachability request
+//
+// RuleError is returned when url is rejected, context errors are returned as is
+func (p *Policy) CheckContext(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return &RuleError{Rule: RuleHost, Reason: "url has no host"}
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	r := p.rules.Load()
+	if domain, ok := matchDomain(host, r.block); ok {
+		return &RuleError{Rule: RuleBlocklist + ":" + domain, Reason: fmt.Sprintf("host %s is blocked", host)}
+	}
+	if len(r.allow) > 0 {
+		if _, ok := matchDomain(host, r.allow); !ok {
+			return &RuleError{Rule: RuleAllowlist, Reason: fmt.Sprintf("host %s is not allowed", host)}
+		}
+	}
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(raw) {
+			return &RuleError{Rule: RulePattern + ":" + pattern.String(), Reason: "url matches blocked pattern"}
+		}
+	}
+
+	if p.client != nil {
+		return p.checkReachable(ctx, raw)
+	}
+
+	return nil
+}
+
+// checkReachable sends HEAD request to destination, GET is used when server doesn't support HEAD
+func (p *Policy) checkReachable(ctx context.Context, raw string) error {
+	code, err := p.probe(ctx, http.MethodHead, raw)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = p.probe(ctx, http.MethodGet, raw)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &RuleError{Rule: RuleReachability, Reason: "destination is unreachable"}
+	}
+	if code >= http.StatusBadRequest {
+		return &RuleError{Rule: RuleReachability, Reason: fmt.Sprintf("destination responded with %d", code)}
+	}
+
+	return nil
+}
+
+func (p *Policy) probe(ctx context.Context, method string, raw string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, raw, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer resp.Body.Close()
+	// Body isn't needed, it is partially drained, so connection can be reused
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+
+	return resp.StatusCode, nil
+}
+
+// matchDomain returns domain of the list which is host itself or its parent domain
+func matchDomain(host string, domains []string) (string, bool) {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, true
+		}
+	}
+
+	return "", false
+}
+
+func readDomains(path string) ([]string, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, 0, len(lines))
+	for _, line := range lines {
+		// Wildcard and leading dot mean the same as plain domain, which matches subdomains
+		domain := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(line), "*"), ".")
+		ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+		if err != nil || ascii == "" {
+			return nil, fmt.Errorf("%w: domain %q in %s is not valid", ErrInternal, line, path)
+		}
+		domains = append(domains, ascii)
+	}
+
+	return domains, nil
+}
+
+func readPatterns(path string) ([]*regexp.Regexp, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(lines))
+	for _, line := range lines {
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pattern %q in %s: %v", ErrInternal, line, path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// readLines returns not empty and not commented lines of file, empty path means no lines
+func readLines(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInternal, err)
+	}
+
+	return lines, nil
+}
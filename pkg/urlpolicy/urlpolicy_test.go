@@ -0,0 +1,205 @@
+package urlpolicy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestCheck_TestCases(t *testing.T) {
+	blocklist := writeFile(t, "# phishing\nevil.com\n*.bad.org\n\nbücher.de\n")
+	allowlist := writeFile(t, "google.com\nevil.com\nxn--bcher-kva.de\nexample.com\n")
+	patterns := writeFile(t, `(?i)\.exe$`+"\n"+`/wp-admin/`+"\n")
+
+	tc := []struct {
+		name     string
+		files    Files
+		raw      string
+		wantRule string
+	}{
+		{
+			name: "no rules",
+			raw:  "https://evil.com/",
+		},
+		{
+			name:     "blocked domain",
+			files:    Files{Blocklist: blocklist},
+			raw:      "https://evil.com/login",
+			wantRule: "blocklist:evil.com",
+		},
+		{
+			name:     "blocked subdomain",
+			files:    Files{Blocklist: blocklist},
+			raw:      "https://WWW.Evil.com./login",
+			wantRule: "blocklist:evil.com",
+		},
+		{
+			name:     "blocked wildcard",
+			files:    Files{Blocklist: blocklist},
+			raw:      "https://a.bad.org/",
+			wantRule: "blocklist:bad.org",
+		},
+		{
+			name:     "blocked internationalized domain",
+			files:    Files{Blocklist: blocklist},
+			raw:      "https://xn--bcher-kva.de/",
+			wantRule: "blocklist:xn--bcher-kva.de",
+		},
+		{
+			name:  "not blocked domain with same suffix",
+			files: Files{Blocklist: blocklist},
+			raw:   "https://notevil.com/",
+		},
+		{
+			name:  "allowed",
+			files: Files{Allowlist: allowlist},
+			raw:   "https://mail.google.com/",
+		},
+		{
+			name:     "not allowed",
+			files:    Files{Allowlist: allowlist},
+			raw:      "https://github.com/",
+			wantRule: "allowlist",
+		},
+		{
+			name:     "blocklist wins over allowlist",
+			files:    Files{Blocklist: blocklist, Allowlist: allowlist},
+			raw:      "https://evil.com/",
+			wantRule: "blocklist:evil.com",
+		},
+		{
+			name:     "pattern",
+			files:    Files{Patterns: patterns},
+			raw:      "https://example.com/setup.EXE",
+			wantRule: `pattern:(?i)\.exe$`,
+		},
+		{
+			name:  "pattern not matched",
+			files: Files{Patterns: patterns},
+			raw:   "https://example.com/setup.exe.html",
+		},
+		{
+			name:     "no host",
+			raw:      "/relative",
+			wantRule: "host",
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.files)
+			require.NoError(t, err)
+
+			err = p.Check(tt.raw)
+			if tt.wantRule == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrRejected)
+			var ruleErr *RuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, tt.wantRule, ruleErr.Rule)
+		})
+	}
+}
+
+func TestNewPolicy_InvalidFiles(t *testing.T) {
+	_, err := NewPolicy(Files{Blocklist: filepath.Join(t.TempDir(), "missing.txt")})
+	assert.ErrorIs(t, err, ErrInternal)
+
+	_, err = NewPolicy(Files{Patterns: writeFile(t, "([a-z]\n")})
+	assert.ErrorIs(t, err, ErrInternal)
+}
+
+func TestReload(t *testing.T) {
+	path := writeFile(t, "evil.com\n")
+	p, err := NewPolicy(Files{Blocklist: path})
+	require.NoError(t, err)
+	assert.Error(t, p.Check("https://evil.com/"))
+	assert.NoError(t, p.Check("https://other.com/"))
+
+	require.NoError(t, os.WriteFile(path, []byte("other.com\n"), 0o644))
+	require.NoError(t, p.Reload())
+	assert.NoError(t, p.Check("https://evil.com/"))
+	assert.Error(t, p.Check("https://other.com/"))
+
+	// Broken file keeps previous rules
+	require.NoError(t, os.Remove(path))
+	assert.ErrorIs(t, p.Reload(), ErrInternal)
+	assert.Error(t, p.Check("https://other.com/"))
+}
+
+func TestReachability_TestCases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tc := []struct {
+		name     string
+		raw      string
+		wantRule bool
+	}{
+		{name: "ok", raw: srv.URL + "/ok"},
+		{name: "head not allowed", raw: srv.URL + "/no-head"},
+		{name: "redirect", raw: srv.URL + "/redirect"},
+		{name: "not found", raw: srv.URL + "/missing", wantRule: true},
+		{name: "unreachable", raw: closed.URL + "/ok", wantRule: true},
+	}
+
+	p, err := NewPolicy(Files{}, WithReachability(srv.Client()))
+	require.NoError(t, err)
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.raw)
+			if !tt.wantRule {
+				assert.NoError(t, err)
+				return
+			}
+			var ruleErr *RuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, RuleReachability, ruleErr.Rule)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.CheckContext(ctx, srv.URL+"/ok"), context.Canceled)
+}
+
+func TestPublicClient(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := PublicClient(time.Second).Get(srv.URL)
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
@@ -17,7 +17,7 @@ func TestUrlShorten_OkWithValidRedirect(t *testing.T) {
 
 	validUrl := "https://github.com/maxik12233"
 
-	link, err := s.Client.MakeShortLink(context.Background(), &proto.Link{Data: validUrl})
+	link, err := s.Client.MakeShortLink(context.Background(), &proto.ShortLinkRequest{Data: validUrl})
 	require.NoError(t, err)
 
 	// Make a request on gotten shortened url